@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"submit_do_it/constants"
+	"submit_do_it/delivery/grpcapi"
+	"submit_do_it/delivery/grpcapi/pb"
+	"submit_do_it/delivery/httpapi"
+	"submit_do_it/domain"
+	"submit_do_it/layout"
+	"submit_do_it/payment"
+	"submit_do_it/pricing"
+	"submit_do_it/store"
+	"submit_do_it/usecases"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "HTTP listen address")
+	grpcAddr := flag.String("grpc-addr", "", "optional gRPC listen address")
+	typesPath := flag.String("types", "", "optional file declaring extra vehicle and spot types and fallbacks (.json or .yaml)")
+	layoutPath := flag.String("layout", "", "lot layout file (.json, .yaml, .csv or .txt)")
+	pricingPath := flag.String("pricing", "", "optional pricing file (.json or .yaml)")
+	labelsPath := flag.String("labels", "", "optional file naming floors and rows, so spots can be given as labels such as L2-B-14 (.json or .yaml)")
+	fakePayments := flag.Bool("fake-payments", false, "collect fees through the in-memory fake gateway")
+	snapshotPath := flag.String("snapshot", "", "optional state file, restored at startup if present and saved on shutdown")
+	eventLogPath := flag.String("event-log", "", "optional write-ahead event log, replayed onto -snapshot at startup")
+	fsync := flag.String("fsync", "always", "event log fsync policy: always, never, or an interval such as 200ms")
+	dbPath := flag.String("db", "", "optional SQLite database holding the lot, instead of -snapshot and -event-log")
+	compactEvery := flag.Duration("compact-every", 0, "fold the event log into the snapshot this often (0 only at startup and shutdown)")
+	grace := flag.Duration("reservation-grace", usecases.DefaultReservationGrace, "how long a reserved spot waits for a late vehicle")
+	waitlist := flag.String("waitlist", "", "let vehicles turned away from a full lot wait for a spot, served fifo or by priority")
+	claim := flag.Duration("waitlist-claim", usecases.DefaultWaitlistClaim, "how long a spot offered from the waitlist waits for its vehicle")
+	flag.Parse()
+
+	var opts []usecases.Option
+	if *typesPath != "" {
+		config, err := constants.ReadTypes(*typesPath)
+		if err == nil {
+			err = config.Register(constants.Default)
+		}
+		if err != nil {
+			log.Fatalf("load types: %v", err)
+		}
+		opts = append(opts, usecases.WithCompatibility(config.Compatibility))
+	}
+	if *pricingPath != "" {
+		engine, err := pricing.Load(*pricingPath)
+		if err != nil {
+			log.Fatalf("load pricing: %v", err)
+		}
+		opts = append(opts, usecases.WithPricer(engine))
+	}
+	if *labelsPath != "" {
+		labels, err := layout.LoadLabels(*labelsPath)
+		if err != nil {
+			log.Fatalf("load labels: %v", err)
+		}
+		opts = append(opts, usecases.WithSpotLabels(labels))
+	}
+	if *waitlist != "" {
+		order, err := usecases.ParseWaitlistOrder(*waitlist)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		opts = append(opts, usecases.WithWaitlist(order, *claim))
+	}
+	if *fakePayments {
+		opts = append(opts, usecases.WithPaymentGateway(payment.NewFakeGateway()))
+	}
+	opts = append(opts, usecases.WithReservationGrace(*grace))
+
+	var (
+		pl       usecases.ParkinglotUsecase
+		eventLog *store.EventLog
+	)
+	if *dbPath != "" {
+		if *snapshotPath != "" || *eventLogPath != "" {
+			log.Fatal("-db cannot be combined with -snapshot or -event-log")
+		}
+		repo, err := openDatabase(*dbPath, *layoutPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer repo.Close()
+		if pl, err = usecases.NewParkingLotUsecaseFromRepository(repo, opts...); err != nil {
+			log.Fatalf("load lot from %s: %v", *dbPath, err)
+		}
+	} else {
+		snap, err := initialState(*layoutPath, *snapshotPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var events []domain.Event
+		if *eventLogPath != "" {
+			if *snapshotPath == "" {
+				log.Fatal("-event-log requires -snapshot")
+			}
+			policy, interval, err := parseFsync(*fsync)
+			if err != nil {
+				log.Fatal(err)
+			}
+			eventLog, events, err = store.OpenEventLog(*eventLogPath, policy, interval)
+			if err != nil {
+				log.Fatalf("open event log: %v", err)
+			}
+			defer eventLog.Close()
+			if n := eventLog.Dropped(); n > 0 {
+				log.Printf("dropped %d byte(s) of torn records from %s", n, *eventLogPath)
+			}
+			opts = append(opts, usecases.WithEventRecorder(eventLog))
+		}
+
+		if pl, err = usecases.NewParkingLotUsecaseFromEvents(snap, events, opts...); err != nil {
+			log.Fatalf("restore lot: %v", err)
+		}
+		log.Printf("restored %d ticket(s) and replayed %d event(s)", len(snap.Tickets), len(events))
+		if eventLog != nil {
+			if err := eventLog.Compact(*snapshotPath, pl.Snapshot()); err != nil {
+				log.Fatalf("compact event log: %v", err)
+			}
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if eventLog != nil && *compactEvery > 0 {
+		go func() {
+			ticker := time.NewTicker(*compactEvery)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := eventLog.Compact(*snapshotPath, pl.Snapshot()); err != nil {
+						log.Printf("compact event log: %v", err)
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			log.Fatalf("listen gRPC: %v", err)
+		}
+		g := grpc.NewServer()
+		pb.RegisterParkingLotServer(g, grpcapi.NewServer(pl))
+		go func() {
+			<-ctx.Done()
+			g.GracefulStop()
+		}()
+		go func() {
+			if err := g.Serve(lis); err != nil {
+				log.Fatalf("serve gRPC: %v", err)
+			}
+		}()
+		log.Printf("serving gRPC on %s", *grpcAddr)
+	}
+
+	log.Printf("serving HTTP on %s", *addr)
+	if err := httpapi.NewServer(pl).ListenAndServe(ctx, *addr, 10*time.Second); err != nil {
+		log.Fatal(err)
+	}
+	switch {
+	case eventLog != nil:
+		if err := eventLog.Compact(*snapshotPath, pl.Snapshot()); err != nil {
+			log.Fatalf("compact event log: %v", err)
+		}
+		log.Printf("saved snapshot to %s", *snapshotPath)
+	case *snapshotPath != "":
+		if err := store.SaveSnapshot(*snapshotPath, pl.Snapshot()); err != nil {
+			log.Fatalf("save snapshot: %v", err)
+		}
+		log.Printf("saved snapshot to %s", *snapshotPath)
+	}
+	log.Print("shut down")
+}
+
+// initialState loads the snapshot at snapshotPath when it exists and snapshots a fresh
+// lot built from layoutPath otherwise.
+func initialState(layoutPath, snapshotPath string) (*domain.Snapshot, error) {
+	if snapshotPath != "" {
+		snap, err := store.LoadSnapshot(snapshotPath)
+		if err == nil {
+			return snap, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("load snapshot: %w", err)
+		}
+	}
+
+	if layoutPath == "" {
+		return nil, errors.New("-layout is required")
+	}
+	lot, err := layout.LoadFile(layoutPath)
+	if err != nil {
+		return nil, fmt.Errorf("load layout: %w", err)
+	}
+	pl, err := usecases.NewParkingLotUsecaseFromFloorsE(lot.Templates())
+	if err != nil {
+		return nil, fmt.Errorf("build lot: %w", err)
+	}
+	return pl.Snapshot(), nil
+}
+
+// openDatabase opens the SQLite database at path, storing the layout at layoutPath in it
+// the first time.
+func openDatabase(path, layoutPath string) (*store.SQLiteRepository, error) {
+	repo, err := store.OpenSQLite(path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	floors, err := repo.Layout()
+	if err != nil {
+		repo.Close()
+		return nil, fmt.Errorf("load layout from database: %w", err)
+	}
+	if floors != nil {
+		return repo, nil
+	}
+
+	if layoutPath == "" {
+		repo.Close()
+		return nil, errors.New("-layout is required for a new database")
+	}
+	lot, err := layout.LoadFile(layoutPath)
+	if err == nil {
+		err = repo.InitLayout(lot.Templates())
+	}
+	if err != nil {
+		repo.Close()
+		return nil, fmt.Errorf("load layout: %w", err)
+	}
+	return repo, nil
+}
+
+func parseFsync(policy string) (store.SyncPolicy, time.Duration, error) {
+	switch policy {
+	case "always":
+		return store.SyncAlways, 0, nil
+	case "never":
+		return store.SyncNever, 0, nil
+	}
+	interval, err := time.ParseDuration(policy)
+	if err != nil || interval <= 0 {
+		return 0, 0, fmt.Errorf("invalid -fsync %q, want always, never or a positive interval", policy)
+	}
+	return store.SyncInterval, interval, nil
+}
@@ -0,0 +1,244 @@
+package constants
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TypeSpec declares a vehicle type and the spots that take it. The type doubles as the
+// spot type in layout codes: a type "EV" has spots written "EV-1" and "EV-0".
+type TypeSpec struct {
+	Type VehicleType `json:"code" yaml:"code"`
+	Name string      `json:"name" yaml:"name"`
+	// Symbol is the single upper-case character drawing the spot in ASCII plans. Types
+	// without one cannot appear in ASCII plans.
+	Symbol string `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+	// Spots is how many adjacent spots in one row a vehicle of the type takes, e.g. 3 for a
+	// bus. Zero means one.
+	Spots int `json:"spots,omitempty" yaml:"spots,omitempty"`
+}
+
+// SpotCount returns how many adjacent spots a vehicle of the type takes.
+func (s TypeSpec) SpotCount() int {
+	return max(s.Spots, 1)
+}
+
+// Registry holds the known vehicle types in registration order. It is safe for
+// concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	specs []TypeSpec
+}
+
+var builtinTypes = []TypeSpec{
+	{Type: Bicycle, Name: "bicycle", Symbol: "B"},
+	{Type: Motorcycle, Name: "motorcycle", Symbol: "M"},
+	{Type: Automobile, Name: "automobile", Symbol: "A"},
+}
+
+// NewRegistry returns a registry holding the built-in types B, M and A plus specs.
+func NewRegistry(specs ...TypeSpec) (*Registry, error) {
+	r := &Registry{specs: append([]TypeSpec(nil), builtinTypes...)}
+	if err := r.Register(specs...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Register adds types. Nothing is added if any of them is invalid or already known.
+func (r *Registry) Register(specs ...TypeSpec) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := append(append([]TypeSpec(nil), r.specs...), specs...)
+	for i, spec := range specs {
+		if err := validateSpec(spec, all[:len(r.specs)+i]); err != nil {
+			return fmt.Errorf("vehicle type %q: %w", spec.Type, err)
+		}
+	}
+	r.specs = all
+	return nil
+}
+
+func validateSpec(spec TypeSpec, known []TypeSpec) error {
+	code := string(spec.Type)
+	if code == "" || len(code) > 8 || !unicode.IsUpper(rune(code[0])) || strings.TrimFunc(code, isCodeRune) != "" {
+		return fmt.Errorf("code must be up to 8 upper-case letters and digits, starting with a letter")
+	}
+	if spec.Symbol != "" {
+		symbol, size := utf8.DecodeRuneInString(spec.Symbol)
+		if size != len(spec.Symbol) || !unicode.IsUpper(symbol) {
+			return fmt.Errorf("symbol %q must be a single upper-case letter", spec.Symbol)
+		}
+	}
+	if spec.Spots < 0 {
+		return fmt.Errorf("negative spot count %d", spec.Spots)
+	}
+	for _, other := range known {
+		if other.Type == spec.Type {
+			return fmt.Errorf("already registered")
+		}
+		if spec.Symbol != "" && other.Symbol == spec.Symbol {
+			return fmt.Errorf("symbol %q already used by %q", spec.Symbol, other.Type)
+		}
+	}
+	return nil
+}
+
+func isCodeRune(c rune) bool {
+	return c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// Types returns every registered type in registration order.
+func (r *Registry) Types() []VehicleType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]VehicleType, len(r.specs))
+	for i, spec := range r.specs {
+		types[i] = spec.Type
+	}
+	return types
+}
+
+// Lookup returns the spec of a registered type.
+func (r *Registry) Lookup(vt VehicleType) (TypeSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, spec := range r.specs {
+		if spec.Type == vt {
+			return spec, true
+		}
+	}
+	return TypeSpec{}, false
+}
+
+// LookupSymbol returns the spec of the type drawn as symbol in ASCII plans.
+func (r *Registry) LookupSymbol(symbol string) (TypeSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, spec := range r.specs {
+		if spec.Symbol != "" && spec.Symbol == symbol {
+			return spec, true
+		}
+	}
+	return TypeSpec{}, false
+}
+
+// Default is the registry layouts, lots and front ends consult. Register types in it at
+// startup, before any lot is built: a lot only knows the types registered when it was
+// created.
+var Default, _ = NewRegistry()
+
+// Register adds types to Default.
+func Register(specs ...TypeSpec) error {
+	return Default.Register(specs...)
+}
+
+// Types returns the types registered in Default.
+func Types() []VehicleType {
+	return Default.Types()
+}
+
+// Lookup returns the spec of a type registered in Default.
+func Lookup(vt VehicleType) (TypeSpec, bool) {
+	return Default.Lookup(vt)
+}
+
+// Compatibility lists, for each vehicle type, the spot types it may park in, most
+// preferred first. Types without an entry only use spots of their own type.
+type Compatibility map[VehicleType][]VehicleType
+
+// SpotTypes returns the spot types vt may use, most preferred first.
+func (c Compatibility) SpotTypes(vt VehicleType) []VehicleType {
+	if spotTypes, ok := c[vt]; ok {
+		return spotTypes
+	}
+	return []VehicleType{vt}
+}
+
+// Validate checks that every type in c is registered in r and that no list repeats a type.
+func (c Compatibility) Validate(r *Registry) error {
+	for vt, spotTypes := range c {
+		if _, ok := r.Lookup(vt); !ok {
+			return fmt.Errorf("compatibility: unknown vehicle type %q", vt)
+		}
+		if len(spotTypes) == 0 {
+			return fmt.Errorf("compatibility %s: no spot types", vt)
+		}
+		for i, st := range spotTypes {
+			if _, ok := r.Lookup(st); !ok {
+				return fmt.Errorf("compatibility %s: unknown spot type %q", vt, st)
+			}
+			if slices.Contains(spotTypes[:i], st) {
+				return fmt.Errorf("compatibility %s: spot type %q listed twice", vt, st)
+			}
+		}
+	}
+	return nil
+}
+
+// TypesConfig is the content of a types file.
+type TypesConfig struct {
+	Types         []TypeSpec    `json:"types" yaml:"types"`
+	Compatibility Compatibility `json:"compatibility,omitempty" yaml:"compatibility,omitempty"`
+}
+
+// Register adds the declared types to r and validates the compatibility rules against it.
+// Nothing is added unless both the types and the rules are valid.
+func (c *TypesConfig) Register(r *Registry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	candidate := &Registry{specs: append([]TypeSpec(nil), r.specs...)}
+	if err := candidate.Register(c.Types...); err != nil {
+		return err
+	}
+	if err := c.Compatibility.Validate(candidate); err != nil {
+		return err
+	}
+	r.specs = candidate.specs
+	return nil
+}
+
+// ReadTypes reads type declarations and compatibility rules from a JSON or YAML file.
+//
+//	types:
+//	  - {code: EV, name: EV charging, symbol: E}
+//	  - {code: H, name: accessible, symbol: H}
+//	  - {code: C, name: compact, symbol: C}
+//	  - {code: T, name: truck, symbol: T, spots: 2}
+//	  - {code: BUS, name: bus, spots: 3}
+//	compatibility:
+//	  M: [M, A]        # motorcycles fall back to car spots
+//	  C: [C, A]
+//	  EV: [EV, A]
+//	  BUS: [A]         # buses take three adjacent car spots
+func ReadTypes(path string) (*TypesConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config TypesConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &config)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &config)
+	default:
+		return nil, fmt.Errorf("unsupported types file extension %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode types %s: %w", path, err)
+	}
+	return &config, nil
+}
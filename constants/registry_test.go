@@ -0,0 +1,107 @@
+package constants
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	r, err := NewRegistry(TypeSpec{Type: "EV", Name: "EV charging", Symbol: "E"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []VehicleType{Bicycle, Motorcycle, Automobile, "EV"}; !slices.Equal(r.Types(), want) {
+		t.Errorf("Types() = %v, want %v", r.Types(), want)
+	}
+	if spec, ok := r.LookupSymbol("E"); !ok || spec.Type != "EV" {
+		t.Errorf("LookupSymbol(E) = %+v, %v", spec, ok)
+	}
+
+	tests := []struct {
+		name string
+		spec TypeSpec
+	}{
+		{"Empty", TypeSpec{}},
+		{"Lowercase", TypeSpec{Type: "ev"}},
+		{"Dash", TypeSpec{Type: "E-V"}},
+		{"LeadingDigit", TypeSpec{Type: "1T"}},
+		{"TooLong", TypeSpec{Type: "OVERSIZED"}},
+		{"Duplicate", TypeSpec{Type: "EV"}},
+		{"Builtin", TypeSpec{Type: Automobile}},
+		{"LowercaseSymbol", TypeSpec{Type: "T", Symbol: "t"}},
+		{"LongSymbol", TypeSpec{Type: "T", Symbol: "TR"}},
+		{"SymbolTaken", TypeSpec{Type: "T", Symbol: "A"}},
+		{"NegativeSpots", TypeSpec{Type: "T", Spots: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Register(tt.spec); err == nil {
+				t.Errorf("Register(%+v) should fail", tt.spec)
+			}
+		})
+	}
+
+	if err := r.Register(TypeSpec{Type: "T"}, TypeSpec{Type: "T"}); err == nil {
+		t.Error("registering a type twice in one call should fail")
+	}
+	if _, ok := r.Lookup("T"); ok {
+		t.Error("a failed Register added types")
+	}
+}
+
+func TestReadTypes(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "types.yaml")
+	os.WriteFile(yamlPath, []byte("types:\n  - {code: BUS, name: bus, symbol: U, spots: 3}\n  - {code: H, name: accessible}\ncompatibility:\n  H: [H, A]\n"), 0o644)
+	jsonPath := filepath.Join(dir, "types.json")
+	os.WriteFile(jsonPath, []byte(`{"types": [{"code": "BUS", "name": "bus", "symbol": "U", "spots": 3}, {"code": "H", "name": "accessible"}]}`), 0o644)
+
+	want := []TypeSpec{{Type: "BUS", Name: "bus", Symbol: "U", Spots: 3}, {Type: "H", Name: "accessible"}}
+	for _, path := range []string{yamlPath, jsonPath} {
+		config, err := ReadTypes(path)
+		if err != nil || !slices.Equal(config.Types, want) {
+			t.Errorf("ReadTypes(%s) = %+v, %v", filepath.Base(path), config, err)
+		}
+	}
+	if _, err := ReadTypes(filepath.Join(dir, "types.toml")); err == nil {
+		t.Error("expected error for unsupported extension")
+	}
+}
+
+func TestTypesConfig_Register(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TypesConfig
+		wantErr bool
+	}{
+		{"Fallback", TypesConfig{Compatibility: Compatibility{Motorcycle: {Motorcycle, Automobile}}}, false},
+		{"NewType", TypesConfig{Types: []TypeSpec{{Type: "EV"}}, Compatibility: Compatibility{"EV": {"EV", Automobile}}}, false},
+		{"UnknownVehicle", TypesConfig{Compatibility: Compatibility{"X": {Automobile}}}, true},
+		{"UnknownSpot", TypesConfig{Compatibility: Compatibility{Motorcycle: {"X"}}}, true},
+		{"Empty", TypesConfig{Compatibility: Compatibility{Motorcycle: {}}}, true},
+		{"Repeated", TypesConfig{Compatibility: Compatibility{Motorcycle: {Automobile, Automobile}}}, true},
+		{"NewTypeBadRule", TypesConfig{Types: []TypeSpec{{Type: "EV"}}, Compatibility: Compatibility{"EV": {"X"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := NewRegistry()
+			err := tt.config.Register(r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Register() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(r.Types()); err != nil && got != len(builtinTypes) {
+				t.Errorf("%d types registered after a failed Register, want %d", got, len(builtinTypes))
+			}
+		})
+	}
+
+	c := Compatibility{Motorcycle: {Motorcycle, Automobile}}
+	if got := c.SpotTypes(Motorcycle); !slices.Equal(got, []VehicleType{Motorcycle, Automobile}) {
+		t.Errorf("SpotTypes(M) = %v", got)
+	}
+	if got := c.SpotTypes(Bicycle); !slices.Equal(got, []VehicleType{Bicycle}) {
+		t.Errorf("SpotTypes(B) = %v", got)
+	}
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"submit_do_it/usecases"
+	"sync"
+	"time"
+)
+
+// OutputFormat selects how Batch reports results.
+type OutputFormat string
+
+const (
+	TextOutput OutputFormat = "text"
+	JSONOutput OutputFormat = "json"
+)
+
+// Result is the outcome of one batch command.
+type Result struct {
+	Line    int      `json:"line"`
+	Command string   `json:"command"`
+	OK      bool     `json:"ok"`
+	Output  []string `json:"output,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	Code    string   `json:"code,omitempty"` // see ErrorCode
+}
+
+// Batch runs a file of shell commands and reports exactly one result line per command,
+// so runs can be diffed against golden files. A line may start with an RFC 3339
+// timestamp, which becomes the lot's clock from that command on; replaying gate logs
+// with timestamps makes tickets, durations and fees reproducible.
+type Batch struct {
+	*Shell
+	Format          OutputFormat
+	ContinueOnError bool
+
+	buf   bytes.Buffer
+	mu    sync.Mutex
+	clock time.Time
+}
+
+// NewBatch returns a batch runner. opts are applied to every lot it creates, after the
+// replay clock, so an explicit WithClock still wins.
+func NewBatch(opts ...usecases.Option) *Batch {
+	b := &Batch{Format: TextOutput}
+	opts = append([]usecases.Option{usecases.WithClock(b.now)}, opts...)
+	b.Shell = NewShell(&b.buf, opts...)
+	return b
+}
+
+func (b *Batch) now() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.clock.IsZero() {
+		return time.Now()
+	}
+	return b.clock
+}
+
+// Run executes every command from in, writing results to out. It returns the ExitCode of
+// the first command that failed; unless ContinueOnError is set the run stops there.
+func (b *Batch) Run(in io.Reader, out io.Writer) int {
+	if b.Format != TextOutput && b.Format != JSONOutput {
+		fmt.Fprintf(out, "unknown output format %q\n", b.Format)
+		return ExitUsage
+	}
+
+	scanner := bufio.NewScanner(in)
+	code := 0
+	for n := 1; !b.quit && scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		result, err := b.exec(n, line)
+		if err := b.write(out, result); err != nil {
+			return ExitUsage
+		}
+		if err != nil {
+			if code == 0 {
+				code = ExitCode(err)
+			}
+			if !b.ContinueOnError {
+				break
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(out, "read commands: %v\n", err)
+		return ExitUsage
+	}
+	return code
+}
+
+func (b *Batch) exec(n int, line string) (Result, error) {
+	result := Result{Line: n, Command: line}
+	if stamp, rest, ok := strings.Cut(line, " "); ok {
+		if t, err := time.Parse(time.RFC3339, stamp); err == nil {
+			b.mu.Lock()
+			b.clock = t
+			b.mu.Unlock()
+			line = strings.TrimSpace(rest)
+		}
+	}
+
+	b.buf.Reset()
+	err := b.Exec(line)
+	if output := strings.TrimRight(b.buf.String(), "\n"); output != "" {
+		result.Output = strings.Split(output, "\n")
+	}
+	result.OK = err == nil
+	if err != nil {
+		result.Error = err.Error()
+		result.Code = ErrorCode(err)
+	}
+	return result, err
+}
+
+func (b *Batch) write(out io.Writer, r Result) error {
+	if b.Format == JSONOutput {
+		return json.NewEncoder(out).Encode(r)
+	}
+
+	status := "ok"
+	detail := strings.Join(r.Output, " | ")
+	if !r.OK {
+		status = "error"
+		detail = r.Code + ": " + r.Error
+	}
+	if detail == "" {
+		_, err := fmt.Fprintf(out, "%d %s\n", r.Line, status)
+		return err
+	}
+	_, err := fmt.Fprintf(out, "%d %s %s\n", r.Line, status, detail)
+	return err
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const gateLog = `# morning gate log
+2024-05-01T08:00:00Z create_lot 1 B-1,A-1
+2024-05-01T08:05:00Z park A CAR1
+park A CAR2
+2024-05-01T09:35:00Z unpark T00000001
+map
+`
+
+func TestBatch_Text(t *testing.T) {
+	tests := []struct {
+		name            string
+		continueOnError bool
+		want            string
+	}{
+		{"StopOnError", false, `2 ok Created lot with 1 floor(s) of up to 1x2 spots
+3 ok Parked CAR1 at 0-0-1 (ticket T00000001)
+4 error lot_full: park vehicle CAR2 type A: no available parking spot for vehicle type
+`},
+		{"ContinueOnError", true, `2 ok Created lot with 1 floor(s) of up to 1x2 spots
+3 ok Parked CAR1 at 0-0-1 (ticket T00000001)
+4 error lot_full: park vehicle CAR2 type A: no available parking spot for vehicle type
+5 ok Unparked CAR1 from 0-0-1 after 1h30m0s
+6 ok [Floor 0] | B A
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBatch()
+			b.ContinueOnError = tt.continueOnError
+			var out strings.Builder
+			if code := b.Run(strings.NewReader(gateLog), &out); code != ExitLotFull {
+				t.Errorf("exit code %d, want %d", code, ExitLotFull)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output:\n%s\nwant:\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBatch_JSON(t *testing.T) {
+	b := NewBatch()
+	b.Format = JSONOutput
+	var out strings.Builder
+	if code := b.Run(strings.NewReader("create_lot 1 B-1\npark B BIKE1\nexit\npark B BIKE2\n"), &out); code != 0 {
+		t.Fatalf("exit code %d, want 0", code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d result lines, want 3:\n%s", len(lines), out.String())
+	}
+	var r Result
+	if err := json.Unmarshal([]byte(lines[1]), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Line != 2 || !r.OK || r.Command != "park B BIKE1" || len(r.Output) != 1 || r.Output[0] != "Parked BIKE1 at 0-0-0 (ticket T00000001)" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+}
+
+func TestBatch_UnknownFormat(t *testing.T) {
+	b := NewBatch()
+	b.Format = "xml"
+	var out strings.Builder
+	if code := b.Run(strings.NewReader("status\n"), &out); code != ExitUsage {
+		t.Errorf("exit code %d, want %d", code, ExitUsage)
+	}
+}
+
+func TestBatch_JSONErrorCode(t *testing.T) {
+	b := NewBatch()
+	b.Format = JSONOutput
+	b.ContinueOnError = true
+	var out strings.Builder
+	code := b.Run(strings.NewReader("park B BIKE1\ncreate_lot 1 B-1\nunpark 0-0-0 BIKE1\n"), &out)
+	if code != ExitUsage {
+		t.Errorf("exit code %d, want %d", code, ExitUsage)
+	}
+
+	var codes []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r Result
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal(err)
+		}
+		codes = append(codes, r.Code)
+	}
+	if want := []string{"usage", "", "vehicle_mismatch"}; strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Errorf("codes %q, want %q", codes, want)
+	}
+}
+
+func TestBatch_Reservation(t *testing.T) {
+	b := NewBatch()
+	b.ContinueOnError = true
+	var out strings.Builder
+	code := b.Run(strings.NewReader(`2024-05-01T08:00:00Z create_lot 1 A-1
+2024-05-01T08:00:00Z reserve A CAR1 2024-05-01T09:00:00Z 2024-05-01T18:00:00Z
+2024-05-01T08:00:00Z reserve A CAR2 tomorrow 2024-05-01T18:00:00Z
+2024-05-01T09:00:00Z park A CAR2
+2024-05-01T09:05:00Z park A CAR1
+`), &out)
+	if code != ExitInvalid {
+		t.Errorf("exit code %d, want %d", code, ExitInvalid)
+	}
+	want := `1 ok Created lot with 1 floor(s) of up to 1x1 spots
+2 ok Reserved R00000001 for CAR1 from 2024-05-01 09:00:00 to 2024-05-01 18:00:00
+3 error invalid_reservation: invalid reservation window: "tomorrow" is not an RFC 3339 time
+4 error lot_full: park vehicle CAR2 type A: no available parking spot for vehicle type
+5 ok Parked CAR1 at 0-0-0 (ticket T00000001)
+`
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,450 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/layout"
+	"submit_do_it/usecases"
+	"time"
+)
+
+func commands() map[string]command {
+	return map[string]command{
+		"create_lot": {
+			usage: "create_lot FILE | create_lot FLOORS ROW...",
+			help:  "build a new lot from a layout file, or stamp comma-separated rows (B-1,M-1,A-1) onto every floor",
+			run:   createLot,
+		},
+		"park": {
+			usage: "park TYPE VEHICLE",
+			help:  "park a vehicle of a type such as B, M or A and print its ticket",
+			run:   park,
+		},
+		"unpark": {
+			usage: "unpark SPOT VEHICLE | unpark TICKET",
+			help:  "release a vehicle by spot and number, or by ticket",
+			run:   unpark,
+		},
+		"available": {
+			usage: "available [TYPE]",
+			help:  "free spots for one vehicle type, or all of them",
+			run:   available,
+		},
+		"search": {
+			usage: "search VEHICLE",
+			help:  "where a vehicle is parked, or was last parked",
+			run:   search,
+		},
+		"history": {
+			usage: "history VEHICLE",
+			help:  "every ticket issued to a vehicle",
+			run:   history,
+		},
+		"status": {
+			usage: "status",
+			help:  "lot size, occupancy and free spots per type",
+			run:   lotStatus,
+		},
+		"map": {
+			usage: "map [FLOOR]",
+			help:  "draw the lot, occupied spots in lower case",
+			run:   drawMap,
+		},
+		"deactivate": {
+			usage: "deactivate FLOOR | FLOOR-ROW | SPOT",
+			help:  "close spots to new vehicles; parked ones stay until they leave",
+			run:   deactivate,
+		},
+		"reactivate": {
+			usage: "reactivate FLOOR | FLOOR-ROW | SPOT",
+			help:  "open closed spots again",
+			run:   reactivate,
+		},
+		"reserve": {
+			usage: "reserve TYPE VEHICLE START END",
+			help:  "hold a spot for a vehicle between two RFC 3339 times",
+			run:   reserve,
+		},
+		"cancel_reservation": {
+			usage: "cancel_reservation RESERVATION",
+			help:  "release a reservation and the spot it holds",
+			run:   cancelReservation,
+		},
+		"wait": {
+			usage: "wait TYPE VEHICLE [PRIORITY]",
+			help:  "queue a vehicle for the next free spot; it is held for the vehicle once offered",
+			run:   joinWaitlist,
+		},
+		"leave_waitlist": {
+			usage: "leave_waitlist VEHICLE",
+			help:  "take a vehicle off the waitlist",
+			run:   leaveWaitlist,
+		},
+		"help": {
+			usage: "help",
+			help:  "show this help",
+			run:   help,
+		},
+		"exit": {
+			usage: "exit",
+			help:  "leave the shell",
+			run:   exit,
+		},
+	}
+}
+
+func createLot(s *Shell, args []string) error {
+	switch {
+	case len(args) == 1:
+		if err := s.LoadLayout(args[0]); err != nil {
+			return err
+		}
+	case len(args) >= 2:
+		floors, err := strconv.Atoi(args[0])
+		if err != nil {
+			return errUsage
+		}
+		template := make([][]string, len(args)-1)
+		for r, row := range args[1:] {
+			template[r] = strings.Split(row, ",")
+		}
+		u, err := usecases.NewParkingLotUsecaseE(floors, len(template), len(template[0]), template, s.opts...)
+		if err != nil {
+			return err
+		}
+		s.usecase = u
+	default:
+		return errUsage
+	}
+
+	floors, rows, columns := dimensions(s.usecase.Snapshot())
+	fmt.Fprintf(s.out, "Created lot with %d floor(s) of up to %dx%d spots\n", floors, rows, columns)
+	return nil
+}
+
+// dimensions returns the number of floors and the largest row and column counts of a lot.
+func dimensions(snap *domain.Snapshot) (floors, rows, columns int) {
+	for _, floor := range snap.Floors {
+		rows = max(rows, len(floor))
+		for _, row := range floor {
+			columns = max(columns, len(row))
+		}
+	}
+	return len(snap.Floors), rows, columns
+}
+
+func park(s *Shell, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+	vt, err := vehicleType(args[0])
+	if err != nil {
+		return err
+	}
+
+	ticket, err := u.Park(vt, args[1])
+	if err != nil {
+		return err
+	}
+	fallback := ""
+	if ticket.ParkedSpotType() != ticket.VehicleType {
+		fallback = fmt.Sprintf(", %s spot", ticket.ParkedSpotType())
+	}
+	fmt.Fprintf(s.out, "Parked %s at %s (ticket %s%s)\n", ticket.VehicleNumber, u.SpotLabel(ticket.SpotRange()), ticket.ID, fallback)
+	return nil
+}
+
+func unpark(s *Shell, args []string) error {
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+
+	var receipt domain.Receipt
+	switch len(args) {
+	case 1:
+		receipt, err = u.UnparkTicket(args[0])
+	case 2:
+		receipt, err = u.Unpark(args[0], args[1])
+	default:
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+
+	ticket := receipt.Ticket
+	fmt.Fprintf(s.out, "Unparked %s from %s after %s", ticket.VehicleNumber, u.SpotLabel(ticket.SpotRange()), ticket.Duration())
+	if receipt.Fee.Amount > 0 {
+		fmt.Fprintf(s.out, ", charged %d %s", receipt.Fee.Amount, receipt.Fee.Currency)
+	}
+	fmt.Fprintln(s.out)
+	return nil
+}
+
+func available(s *Shell, args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+
+	types := constants.Types()
+	if len(args) == 1 {
+		vt, err := vehicleType(args[0])
+		if err != nil {
+			return err
+		}
+		types = []constants.VehicleType{vt}
+	}
+	for _, vt := range types {
+		fmt.Fprintf(s.out, "%s: %d\n", vt, u.AvailableSpot(vt))
+	}
+	return nil
+}
+
+func search(s *Shell, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+
+	location, err := u.SearchVehicle(args[0])
+	if err != nil {
+		return err
+	}
+	if location.Parked {
+		fmt.Fprintf(s.out, "%s is parked at %s\n", args[0], u.SpotLabel(location.SpotRange))
+	} else {
+		fmt.Fprintf(s.out, "%s was last parked at %s\n", args[0], u.SpotLabel(location.SpotRange))
+	}
+	return nil
+}
+
+func history(s *Shell, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+
+	tickets, err := u.VehicleHistory(args[0])
+	if err != nil {
+		return err
+	}
+	for _, t := range tickets {
+		exit := "parked"
+		if !t.Active() {
+			exit = t.ExitTime.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(s.out, "%s %s %s -> %s\n", t.ID, u.SpotLabel(t.SpotRange()), t.EntryTime.Format("2006-01-02 15:04:05"), exit)
+	}
+	return nil
+}
+
+func lotStatus(s *Shell, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+
+	snap := u.Snapshot()
+	floors, rows, columns := dimensions(snap)
+	parked := len(snap.Occupied())
+
+	fmt.Fprintf(s.out, "Floors: %d, up to %dx%d spots per floor\n", floors, rows, columns)
+	fmt.Fprintf(s.out, "Parked vehicles: %d\n", parked)
+	return available(s, nil)
+}
+
+func drawMap(s *Shell, args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		floor, err := strconv.Atoi(args[0])
+		if err != nil {
+			return errUsage
+		}
+		return layout.RenderFloorASCII(s.out, u.Snapshot(), floor)
+	}
+	return layout.RenderASCII(s.out, u.Snapshot())
+}
+
+func deactivate(s *Shell, args []string) error {
+	return setActive(s, args, false)
+}
+
+func reactivate(s *Shell, args []string) error {
+	return setActive(s, args, true)
+}
+
+func setActive(s *Shell, args []string, active bool) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+
+	change, verb := u.Deactivate, "Deactivated"
+	if active {
+		change, verb = u.Reactivate, "Reactivated"
+	}
+	result, err := change(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "%s %d spot(s)\n", verb, len(result.Spots))
+	if len(result.Occupied) > 0 && !active {
+		occupied := make([]string, len(result.Occupied))
+		for i, id := range result.Occupied {
+			occupied[i] = u.SpotLabel(id)
+		}
+		fmt.Fprintf(s.out, "Still occupied: %s\n", strings.Join(occupied, " "))
+	}
+	return nil
+}
+
+func reserve(s *Shell, args []string) error {
+	if len(args) != 4 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+	vt, err := vehicleType(args[0])
+	if err != nil {
+		return err
+	}
+	var window [2]time.Time
+	for i, arg := range args[2:] {
+		if window[i], err = time.Parse(time.RFC3339, arg); err != nil {
+			return fmt.Errorf("%w: %q is not an RFC 3339 time", usecases.ErrInvalidReservation, arg)
+		}
+	}
+
+	r, err := u.Reserve(vt, args[1], window[0], window[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "Reserved %s for %s from %s to %s", r.ID, r.VehicleNumber,
+		r.Start.Format("2006-01-02 15:04:05"), r.End.Format("2006-01-02 15:04:05"))
+	if r.SpotID != "" {
+		fmt.Fprintf(s.out, ", holding %s", u.SpotLabel(r.SpotID))
+	}
+	fmt.Fprintln(s.out)
+	return nil
+}
+
+func cancelReservation(s *Shell, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+	if err := u.CancelReservation(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "Cancelled reservation %s\n", args[0])
+	return nil
+}
+
+func joinWaitlist(s *Shell, args []string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+	vt, err := vehicleType(args[0])
+	if err != nil {
+		return err
+	}
+	priority := 0
+	if len(args) == 3 {
+		if priority, err = strconv.Atoi(args[2]); err != nil {
+			return errUsage
+		}
+	}
+
+	position, err := u.JoinWaitlist(vt, args[1], priority, s.offer)
+	if err != nil {
+		return err
+	}
+	if position > 0 {
+		fmt.Fprintf(s.out, "%s is number %d on the waitlist\n", args[1], position)
+	}
+	return nil
+}
+
+func leaveWaitlist(s *Shell, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	u, err := s.lot()
+	if err != nil {
+		return err
+	}
+	if err := u.LeaveWaitlist(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "%s left the waitlist\n", args[0])
+	return nil
+}
+
+func help(s *Shell, args []string) error {
+	names := make([]string, 0, len(s.commands))
+	for name := range s.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cmd := s.commands[name]
+		fmt.Fprintf(s.out, "  %-36s %s\n", cmd.usage, cmd.help)
+	}
+	return nil
+}
+
+func exit(s *Shell, args []string) error {
+	s.quit = true
+	return nil
+}
+
+func vehicleType(name string) (constants.VehicleType, error) {
+	for _, vt := range constants.Types() {
+		if strings.EqualFold(string(vt), name) {
+			return vt, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", usecases.ErrUnknownVehicleType, name)
+}
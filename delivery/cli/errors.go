@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"errors"
+	"submit_do_it/usecases"
+)
+
+// Exit codes of a script or batch run, chosen by the first command that failed. Usecase
+// errors map by usecases.KindOf, so a script sees the same classes of failure as HTTP
+// and gRPC clients.
+const (
+	ExitOK                 = 0
+	ExitInternal           = 1 // anything not listed below, such as an unreadable layout
+	ExitUsage              = 2 // unknown command, wrong arguments or no lot yet
+	ExitInvalid            = 3 // unknown vehicle type or malformed spot ID
+	ExitNotFound           = 4
+	ExitAlreadyExists      = 5 // vehicle already parked, reserved for that window or on the waitlist
+	ExitLotFull            = 6
+	ExitFailedPrecondition = 7 // ticket closed, refunded or not paid, or a feature not enabled
+	ExitPaymentDeclined    = 8
+)
+
+// usageError is a mistake in the command line itself rather than a failed operation.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func isUsage(err error) bool {
+	var ue usageError
+	return errors.As(err, &ue) || errors.Is(err, errUsage) || errors.Is(err, errNoLot)
+}
+
+// ExitCode returns the process exit code for err, or ExitOK if it is nil.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if isUsage(err) {
+		return ExitUsage
+	}
+	switch usecases.KindOf(err) {
+	case usecases.KindInvalid:
+		return ExitInvalid
+	case usecases.KindNotFound:
+		return ExitNotFound
+	case usecases.KindAlreadyExists:
+		return ExitAlreadyExists
+	case usecases.KindExhausted:
+		return ExitLotFull
+	case usecases.KindFailedPrecondition:
+		return ExitFailedPrecondition
+	case usecases.KindPaymentRequired:
+		return ExitPaymentDeclined
+	}
+	return ExitInternal
+}
+
+// ErrorCode returns the snake_case code batch results report for err: "usage" for
+// command line mistakes, otherwise usecases.CodeOf.
+func ErrorCode(err error) string {
+	if isUsage(err) {
+		return "usage"
+	}
+	return usecases.CodeOf(err)
+}
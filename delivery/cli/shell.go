@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"submit_do_it/domain"
+	"submit_do_it/layout"
+	"submit_do_it/usecases"
+)
+
+var (
+	errNoLot = errors.New("no lot yet, run create_lot first")
+	errUsage = errors.New("wrong arguments")
+)
+
+// Shell runs attendant commands against a single ParkinglotUsecase that lives for the
+// whole session. Lines are read whole and split on whitespace; there is no completion.
+type Shell struct {
+	Prompt string
+
+	out      io.Writer
+	opts     []usecases.Option
+	usecase  usecases.ParkinglotUsecase
+	commands map[string]command
+	quit     bool
+
+	// offers are spots the waitlist held for vehicles that joined it. The usecase hands
+	// them over before the command that freed the spot returns, and Exec prints them
+	// with that command's output.
+	offers []domain.Reservation
+}
+
+type command struct {
+	usage string
+	help  string
+	run   func(s *Shell, args []string) error
+}
+
+// NewShell returns a shell writing to out. opts are applied to every lot it creates.
+func NewShell(out io.Writer, opts ...usecases.Option) *Shell {
+	return &Shell{
+		Prompt:   "> ",
+		out:      out,
+		opts:     opts,
+		commands: commands(),
+	}
+}
+
+// SetUsecase makes the shell operate on an existing lot instead of one from create_lot.
+func (s *Shell) SetUsecase(u usecases.ParkinglotUsecase) {
+	s.usecase = u
+}
+
+// LoadLayout replaces the current lot with one built from a layout file.
+func (s *Shell) LoadLayout(path string) error {
+	lot, err := layout.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	u, err := usecases.NewParkingLotUsecaseFromFloorsE(lot.Templates(), s.opts...)
+	if err != nil {
+		return err
+	}
+	s.usecase = u
+	return nil
+}
+
+// Exec runs a single command line. Blank lines and lines starting with "#" are ignored.
+func (s *Shell) Exec(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return nil
+	}
+
+	cmd, ok := s.commands[strings.ToLower(fields[0])]
+	if !ok {
+		return usageError(fmt.Sprintf("unknown command %q, try help", fields[0]))
+	}
+	err := cmd.run(s, fields[1:])
+	s.printOffers()
+	if errors.Is(err, errUsage) {
+		return usageError("usage: " + cmd.usage)
+	}
+	return err
+}
+
+// Run executes every line from in until EOF or exit. In interactive mode a prompt is shown
+// and failures are only reported; otherwise Run returns the ExitCode of the first command
+// that failed.
+func (s *Shell) Run(in io.Reader, interactive bool) int {
+	scanner := bufio.NewScanner(in)
+	code := 0
+	for !s.quit {
+		if interactive {
+			fmt.Fprint(s.out, s.Prompt)
+		}
+		if !scanner.Scan() {
+			break
+		}
+		if err := s.Exec(scanner.Text()); err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+			if code == 0 {
+				code = ExitCode(err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(s.out, "error: %v\n", err)
+		if code == 0 {
+			code = ExitUsage
+		}
+	}
+
+	if interactive {
+		return 0
+	}
+	return code
+}
+
+// offer queues a spot held from the waitlist to be printed.
+func (s *Shell) offer(r domain.Reservation) {
+	s.offers = append(s.offers, r)
+}
+
+func (s *Shell) printOffers() {
+	for _, r := range s.offers {
+		fmt.Fprintf(s.out, "Offered %s to %s until %s, reservation %s\n",
+			s.usecase.SpotLabel(r.SpotID), r.VehicleNumber, r.End.Format("2006-01-02 15:04:05"), r.ID)
+	}
+	s.offers = nil
+}
+
+func (s *Shell) lot() (usecases.ParkinglotUsecase, error) {
+	if s.usecase == nil {
+		return nil, errNoLot
+	}
+	return s.usecase, nil
+}
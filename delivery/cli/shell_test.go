@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"strings"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/usecases"
+	"testing"
+	"time"
+)
+
+func run(t *testing.T, script string) (string, int) {
+	t.Helper()
+	var out strings.Builder
+	code := NewShell(&out).Run(strings.NewReader(script), false)
+	return out.String(), code
+}
+
+func TestShell_Session(t *testing.T) {
+	out, code := run(t, `
+# two floors of B M A
+create_lot 2 B-1,M-1,A-1
+park B BIKE123
+park A CAR123
+available A
+search CAR123
+unpark 0-0-0 BIKE123
+search BIKE123
+status
+map 0
+`)
+	if code != 0 {
+		t.Fatalf("exit code %d, output:\n%s", code, out)
+	}
+
+	for _, want := range []string{
+		"Created lot with 2 floor(s) of up to 1x3 spots",
+		"Parked BIKE123 at 0-0-0 (ticket T00000001)",
+		"Parked CAR123 at 0-0-2 (ticket T00000002)",
+		"A: 1",
+		"CAR123 is parked at 0-0-2",
+		"Unparked BIKE123 from 0-0-0",
+		"BIKE123 was last parked at 0-0-0",
+		"Parked vehicles: 1",
+		"B: 2",
+		"[Floor 0]\nB M a\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestShell_Deactivate(t *testing.T) {
+	out, code := run(t, `
+create_lot 2 A-1,A-1
+park A CAR1
+deactivate 0
+available A
+reactivate 0-0-1
+available A
+`)
+	if code != 0 {
+		t.Fatalf("exit code %d, output:\n%s", code, out)
+	}
+	want := "Deactivated 2 spot(s)\nStill occupied: 0-0-0\nA: 2\nReactivated 1 spot(s)\nA: 3\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("output missing %q:\n%s", want, out)
+	}
+}
+
+func TestShell_Fallback(t *testing.T) {
+	var out strings.Builder
+	shell := NewShell(&out, usecases.WithCompatibility(constants.Compatibility{
+		constants.Motorcycle: {constants.Motorcycle, constants.Automobile},
+	}))
+	code := shell.Run(strings.NewReader("create_lot 1 M-1,A-1\npark M MOTO1\npark M MOTO2\n"), false)
+	if code != 0 {
+		t.Fatalf("exit code %d, output:\n%s", code, out.String())
+	}
+	want := "Parked MOTO1 at 0-0-0 (ticket T00000001)\nParked MOTO2 at 0-0-1 (ticket T00000002, A spot)\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, out.String())
+	}
+}
+
+func TestShell_SpotLabels(t *testing.T) {
+	var out strings.Builder
+	shell := NewShell(&out, usecases.WithSpotLabels(domain.SpotLabels{FloorPrefix: "L", Base: 1}))
+	code := shell.Run(strings.NewReader("create_lot 1 A-1,A-1\npark A CAR1\nsearch CAR1\nunpark L1-A-1 CAR1\n"), false)
+	if code != 0 {
+		t.Fatalf("exit code %d, output:\n%s", code, out.String())
+	}
+	want := "Parked CAR1 at L1-A-1 (ticket T00000001)\nCAR1 is parked at L1-A-1\nUnparked CAR1 from L1-A-1 after"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, out.String())
+	}
+}
+
+func TestShell_MultiSpot(t *testing.T) {
+	const bus constants.VehicleType = "BUS"
+	if _, known := constants.Lookup(bus); !known {
+		if err := constants.Register(constants.TypeSpec{Type: bus, Name: "bus", Spots: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out strings.Builder
+	shell := NewShell(&out, usecases.WithCompatibility(constants.Compatibility{bus: {constants.Automobile}}))
+	code := shell.Run(strings.NewReader("create_lot 1 A-1,A-1,A-1\npark BUS BUS1\nsearch BUS1\nunpark 0-0-1 BUS1\n"), false)
+	if code != 0 {
+		t.Fatalf("exit code %d, output:\n%s", code, out.String())
+	}
+	for _, want := range []string{
+		"Parked BUS1 at 0-0-0..1 (ticket T00000001, A spot)",
+		"BUS1 is parked at 0-0-0..1",
+		"Unparked BUS1 from 0-0-0..1",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestShell_Waitlist(t *testing.T) {
+	b := NewBatch(usecases.WithWaitlist(usecases.WaitlistFIFO, time.Hour))
+	var out strings.Builder
+	code := b.Run(strings.NewReader(`2024-05-01T08:00:00Z create_lot 1 A-1
+wait A CAR1
+park A CAR1
+wait A CAR2
+wait A CAR3 5
+leave_waitlist CAR3
+2024-05-01T09:00:00Z unpark T00000001
+`), &out)
+	if code != 0 {
+		t.Fatalf("exit code %d, output:\n%s", code, out.String())
+	}
+	want := `1 ok Created lot with 1 floor(s) of up to 1x1 spots
+2 ok Offered 0-0-0 to CAR1 until 2024-05-01 09:00:00, reservation R00000001
+3 ok Parked CAR1 at 0-0-0 (ticket T00000001)
+4 ok CAR2 is number 1 on the waitlist
+5 ok CAR3 is number 2 on the waitlist
+6 ok CAR3 left the waitlist
+7 ok Unparked CAR1 from 0-0-0 after 1h0m0s | Offered 0-0-0 to CAR2 until 2024-05-01 10:00:00, reservation R00000002
+`
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestShell_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+		code   int
+	}{
+		{"NoLot", "park B BIKE123", "no lot yet", ExitUsage},
+		{"UnknownCommand", "fly away", `unknown command "fly"`, ExitUsage},
+		{"Usage", "create_lot 1 B-1\npark B", "usage: park TYPE VEHICLE", ExitUsage},
+		{"UnknownType", "create_lot 1 B-1\npark Z X1", `unknown vehicle type "Z"`, ExitInvalid},
+		{"BadLayout", "create_lot 1 B-1,Q-1", "invalid layout", ExitInternal},
+		{"LotFull", "create_lot 1 B-1\npark B X1\npark B X2", "error:", ExitLotFull},
+		{"AlreadyParked", "create_lot 1 B-1,B-1\npark B X1\npark B X1", "already parked", ExitAlreadyExists},
+		{"AlreadyReserved", "create_lot 1 A-1,A-1\nreserve A X1 2099-05-01T09:00:00Z 2099-05-01T10:00:00Z\nreserve A X1 2099-05-01T09:30:00Z 2099-05-01T11:00:00Z", "already has a reservation", ExitAlreadyExists},
+		{"WaitlistDisabled", "create_lot 1 A-1\nwait A X1", "waitlist not enabled", ExitFailedPrecondition},
+		{"InvalidSpotID", "create_lot 1 B-1\nunpark nowhere X1", "invalid spot ID", ExitInvalid},
+		{"VehicleNotFound", "create_lot 1 B-1\nsearch X1", "vehicle not found", ExitNotFound},
+		{"DeactivateOutsideLot", "create_lot 1 B-1\ndeactivate 3", "spot not found", ExitNotFound},
+		{"FirstFailureWins", "create_lot 1 B-1\nsearch X1\npark Z X1", "unknown vehicle type", ExitNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := run(t, tt.script)
+			if code != tt.code {
+				t.Errorf("exit code %d, want %d", code, tt.code)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output missing %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestShell_InteractiveKeepsGoing(t *testing.T) {
+	var out strings.Builder
+	shell := NewShell(&out)
+	code := shell.Run(strings.NewReader("park B X1\nhelp\nexit\nstatus\n"), true)
+	if code != 0 {
+		t.Errorf("interactive exit code %d, want 0", code)
+	}
+	if !strings.Contains(out.String(), "create_lot FILE") {
+		t.Errorf("help not printed:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "Floors:") {
+		t.Errorf("command after exit was run:\n%s", out.String())
+	}
+}
@@ -0,0 +1,3 @@
+package grpcapi
+
+//go:generate sh -c "cd ../../proto && buf generate"
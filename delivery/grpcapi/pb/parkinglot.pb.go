@@ -0,0 +1,839 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: parkinglot.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Ticket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	VehicleNumber string                 `protobuf:"bytes,2,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"`
+	VehicleType   string                 `protobuf:"bytes,3,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	SpotId        string                 `protobuf:"bytes,4,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+	EntryTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=entry_time,json=entryTime,proto3" json:"entry_time,omitempty"`
+	// Unset while the vehicle is parked.
+	ExitTime  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=exit_time,json=exitTime,proto3" json:"exit_time,omitempty"`
+	PaymentId string                 `protobuf:"bytes,7,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Refunded  bool                   `protobuf:"varint,8,opt,name=refunded,proto3" json:"refunded,omitempty"`
+	// Type of the spot used, which differs from vehicle_type when the vehicle fell back
+	// to a compatible spot type.
+	SpotType string `protobuf:"bytes,9,opt,name=spot_type,json=spotType,proto3" json:"spot_type,omitempty"`
+	// spot_id, or the run of spots held by a vehicle taking several, e.g. "0-1-3..5".
+	SpotRange     string `protobuf:"bytes,10,opt,name=spot_range,json=spotRange,proto3" json:"spot_range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ticket) Reset() {
+	*x = Ticket{}
+	mi := &file_parkinglot_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ticket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ticket) ProtoMessage() {}
+
+func (x *Ticket) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ticket.ProtoReflect.Descriptor instead.
+func (*Ticket) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Ticket) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Ticket) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+func (x *Ticket) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *Ticket) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+func (x *Ticket) GetEntryTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EntryTime
+	}
+	return nil
+}
+
+func (x *Ticket) GetExitTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExitTime
+	}
+	return nil
+}
+
+func (x *Ticket) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *Ticket) GetRefunded() bool {
+	if x != nil {
+		return x.Refunded
+	}
+	return false
+}
+
+func (x *Ticket) GetSpotType() string {
+	if x != nil {
+		return x.SpotType
+	}
+	return ""
+}
+
+func (x *Ticket) GetSpotRange() string {
+	if x != nil {
+		return x.SpotRange
+	}
+	return ""
+}
+
+type Fee struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In minor currency units.
+	Amount        int64  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fee) Reset() {
+	*x = Fee{}
+	mi := &file_parkinglot_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fee) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fee) ProtoMessage() {}
+
+func (x *Fee) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fee.ProtoReflect.Descriptor instead.
+func (*Fee) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Fee) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Fee) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type ParkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VehicleType   string                 `protobuf:"bytes,1,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	VehicleNumber string                 `protobuf:"bytes,2,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParkRequest) Reset() {
+	*x = ParkRequest{}
+	mi := &file_parkinglot_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParkRequest) ProtoMessage() {}
+
+func (x *ParkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParkRequest.ProtoReflect.Descriptor instead.
+func (*ParkRequest) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ParkRequest) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *ParkRequest) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+type ParkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticket        *Ticket                `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParkResponse) Reset() {
+	*x = ParkResponse{}
+	mi := &file_parkinglot_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParkResponse) ProtoMessage() {}
+
+func (x *ParkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParkResponse.ProtoReflect.Descriptor instead.
+func (*ParkResponse) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ParkResponse) GetTicket() *Ticket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+type UnparkRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Either ticket_id, or spot_id together with vehicle_number.
+	TicketId      string `protobuf:"bytes,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	SpotId        string `protobuf:"bytes,2,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+	VehicleNumber string `protobuf:"bytes,3,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnparkRequest) Reset() {
+	*x = UnparkRequest{}
+	mi := &file_parkinglot_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnparkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnparkRequest) ProtoMessage() {}
+
+func (x *UnparkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnparkRequest.ProtoReflect.Descriptor instead.
+func (*UnparkRequest) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UnparkRequest) GetTicketId() string {
+	if x != nil {
+		return x.TicketId
+	}
+	return ""
+}
+
+func (x *UnparkRequest) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+func (x *UnparkRequest) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+type UnparkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticket        *Ticket                `protobuf:"bytes,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	Fee           *Fee                   `protobuf:"bytes,2,opt,name=fee,proto3" json:"fee,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnparkResponse) Reset() {
+	*x = UnparkResponse{}
+	mi := &file_parkinglot_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnparkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnparkResponse) ProtoMessage() {}
+
+func (x *UnparkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnparkResponse.ProtoReflect.Descriptor instead.
+func (*UnparkResponse) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UnparkResponse) GetTicket() *Ticket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+func (x *UnparkResponse) GetFee() *Fee {
+	if x != nil {
+		return x.Fee
+	}
+	return nil
+}
+
+type AvailableSpotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VehicleType   string                 `protobuf:"bytes,1,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AvailableSpotRequest) Reset() {
+	*x = AvailableSpotRequest{}
+	mi := &file_parkinglot_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AvailableSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AvailableSpotRequest) ProtoMessage() {}
+
+func (x *AvailableSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AvailableSpotRequest.ProtoReflect.Descriptor instead.
+func (*AvailableSpotRequest) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AvailableSpotRequest) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+type AvailableSpotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VehicleType   string                 `protobuf:"bytes,1,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	Available     int32                  `protobuf:"varint,2,opt,name=available,proto3" json:"available,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AvailableSpotResponse) Reset() {
+	*x = AvailableSpotResponse{}
+	mi := &file_parkinglot_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AvailableSpotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AvailableSpotResponse) ProtoMessage() {}
+
+func (x *AvailableSpotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AvailableSpotResponse.ProtoReflect.Descriptor instead.
+func (*AvailableSpotResponse) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AvailableSpotResponse) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *AvailableSpotResponse) GetAvailable() int32 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+type SearchVehicleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VehicleNumber string                 `protobuf:"bytes,1,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchVehicleRequest) Reset() {
+	*x = SearchVehicleRequest{}
+	mi := &file_parkinglot_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchVehicleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchVehicleRequest) ProtoMessage() {}
+
+func (x *SearchVehicleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchVehicleRequest.ProtoReflect.Descriptor instead.
+func (*SearchVehicleRequest) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchVehicleRequest) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+type SearchVehicleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SpotId        string                 `protobuf:"bytes,1,opt,name=spot_id,json=spotId,proto3" json:"spot_id,omitempty"`
+	Parked        bool                   `protobuf:"varint,2,opt,name=parked,proto3" json:"parked,omitempty"`
+	Ticket        *Ticket                `protobuf:"bytes,3,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	SpotRange     string                 `protobuf:"bytes,4,opt,name=spot_range,json=spotRange,proto3" json:"spot_range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchVehicleResponse) Reset() {
+	*x = SearchVehicleResponse{}
+	mi := &file_parkinglot_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchVehicleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchVehicleResponse) ProtoMessage() {}
+
+func (x *SearchVehicleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchVehicleResponse.ProtoReflect.Descriptor instead.
+func (*SearchVehicleResponse) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SearchVehicleResponse) GetSpotId() string {
+	if x != nil {
+		return x.SpotId
+	}
+	return ""
+}
+
+func (x *SearchVehicleResponse) GetParked() bool {
+	if x != nil {
+		return x.Parked
+	}
+	return false
+}
+
+func (x *SearchVehicleResponse) GetTicket() *Ticket {
+	if x != nil {
+		return x.Ticket
+	}
+	return nil
+}
+
+func (x *SearchVehicleResponse) GetSpotRange() string {
+	if x != nil {
+		return x.SpotRange
+	}
+	return ""
+}
+
+type WatchAvailabilityRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Empty means every vehicle type.
+	VehicleTypes  []string `protobuf:"bytes,1,rep,name=vehicle_types,json=vehicleTypes,proto3" json:"vehicle_types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAvailabilityRequest) Reset() {
+	*x = WatchAvailabilityRequest{}
+	mi := &file_parkinglot_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAvailabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAvailabilityRequest) ProtoMessage() {}
+
+func (x *WatchAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*WatchAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchAvailabilityRequest) GetVehicleTypes() []string {
+	if x != nil {
+		return x.VehicleTypes
+	}
+	return nil
+}
+
+type AvailabilityUpdate struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Availability  []*AvailableSpotResponse `protobuf:"bytes,1,rep,name=availability,proto3" json:"availability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AvailabilityUpdate) Reset() {
+	*x = AvailabilityUpdate{}
+	mi := &file_parkinglot_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AvailabilityUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AvailabilityUpdate) ProtoMessage() {}
+
+func (x *AvailabilityUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_parkinglot_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AvailabilityUpdate.ProtoReflect.Descriptor instead.
+func (*AvailabilityUpdate) Descriptor() ([]byte, []int) {
+	return file_parkinglot_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AvailabilityUpdate) GetAvailability() []*AvailableSpotResponse {
+	if x != nil {
+		return x.Availability
+	}
+	return nil
+}
+
+var File_parkinglot_proto protoreflect.FileDescriptor
+
+const file_parkinglot_proto_rawDesc = "" +
+	"\n" +
+	"\x10parkinglot.proto\x12\rparkinglot.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe6\x02\n" +
+	"\x06Ticket\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0evehicle_number\x18\x02 \x01(\tR\rvehicleNumber\x12!\n" +
+	"\fvehicle_type\x18\x03 \x01(\tR\vvehicleType\x12\x17\n" +
+	"\aspot_id\x18\x04 \x01(\tR\x06spotId\x129\n" +
+	"\n" +
+	"entry_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tentryTime\x127\n" +
+	"\texit_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bexitTime\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\a \x01(\tR\tpaymentId\x12\x1a\n" +
+	"\brefunded\x18\b \x01(\bR\brefunded\x12\x1b\n" +
+	"\tspot_type\x18\t \x01(\tR\bspotType\x12\x1d\n" +
+	"\n" +
+	"spot_range\x18\n" +
+	" \x01(\tR\tspotRange\"9\n" +
+	"\x03Fee\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"W\n" +
+	"\vParkRequest\x12!\n" +
+	"\fvehicle_type\x18\x01 \x01(\tR\vvehicleType\x12%\n" +
+	"\x0evehicle_number\x18\x02 \x01(\tR\rvehicleNumber\"=\n" +
+	"\fParkResponse\x12-\n" +
+	"\x06ticket\x18\x01 \x01(\v2\x15.parkinglot.v1.TicketR\x06ticket\"l\n" +
+	"\rUnparkRequest\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\tR\bticketId\x12\x17\n" +
+	"\aspot_id\x18\x02 \x01(\tR\x06spotId\x12%\n" +
+	"\x0evehicle_number\x18\x03 \x01(\tR\rvehicleNumber\"e\n" +
+	"\x0eUnparkResponse\x12-\n" +
+	"\x06ticket\x18\x01 \x01(\v2\x15.parkinglot.v1.TicketR\x06ticket\x12$\n" +
+	"\x03fee\x18\x02 \x01(\v2\x12.parkinglot.v1.FeeR\x03fee\"9\n" +
+	"\x14AvailableSpotRequest\x12!\n" +
+	"\fvehicle_type\x18\x01 \x01(\tR\vvehicleType\"X\n" +
+	"\x15AvailableSpotResponse\x12!\n" +
+	"\fvehicle_type\x18\x01 \x01(\tR\vvehicleType\x12\x1c\n" +
+	"\tavailable\x18\x02 \x01(\x05R\tavailable\"=\n" +
+	"\x14SearchVehicleRequest\x12%\n" +
+	"\x0evehicle_number\x18\x01 \x01(\tR\rvehicleNumber\"\x96\x01\n" +
+	"\x15SearchVehicleResponse\x12\x17\n" +
+	"\aspot_id\x18\x01 \x01(\tR\x06spotId\x12\x16\n" +
+	"\x06parked\x18\x02 \x01(\bR\x06parked\x12-\n" +
+	"\x06ticket\x18\x03 \x01(\v2\x15.parkinglot.v1.TicketR\x06ticket\x12\x1d\n" +
+	"\n" +
+	"spot_range\x18\x04 \x01(\tR\tspotRange\"?\n" +
+	"\x18WatchAvailabilityRequest\x12#\n" +
+	"\rvehicle_types\x18\x01 \x03(\tR\fvehicleTypes\"^\n" +
+	"\x12AvailabilityUpdate\x12H\n" +
+	"\favailability\x18\x01 \x03(\v2$.parkinglot.v1.AvailableSpotResponseR\favailability2\xaf\x03\n" +
+	"\n" +
+	"ParkingLot\x12?\n" +
+	"\x04Park\x12\x1a.parkinglot.v1.ParkRequest\x1a\x1b.parkinglot.v1.ParkResponse\x12E\n" +
+	"\x06Unpark\x12\x1c.parkinglot.v1.UnparkRequest\x1a\x1d.parkinglot.v1.UnparkResponse\x12Z\n" +
+	"\rAvailableSpot\x12#.parkinglot.v1.AvailableSpotRequest\x1a$.parkinglot.v1.AvailableSpotResponse\x12Z\n" +
+	"\rSearchVehicle\x12#.parkinglot.v1.SearchVehicleRequest\x1a$.parkinglot.v1.SearchVehicleResponse\x12a\n" +
+	"\x11WatchAvailability\x12'.parkinglot.v1.WatchAvailabilityRequest\x1a!.parkinglot.v1.AvailabilityUpdate0\x01B\"Z submit_do_it/delivery/grpcapi/pbb\x06proto3"
+
+var (
+	file_parkinglot_proto_rawDescOnce sync.Once
+	file_parkinglot_proto_rawDescData []byte
+)
+
+func file_parkinglot_proto_rawDescGZIP() []byte {
+	file_parkinglot_proto_rawDescOnce.Do(func() {
+		file_parkinglot_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_parkinglot_proto_rawDesc), len(file_parkinglot_proto_rawDesc)))
+	})
+	return file_parkinglot_proto_rawDescData
+}
+
+var file_parkinglot_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_parkinglot_proto_goTypes = []any{
+	(*Ticket)(nil),                   // 0: parkinglot.v1.Ticket
+	(*Fee)(nil),                      // 1: parkinglot.v1.Fee
+	(*ParkRequest)(nil),              // 2: parkinglot.v1.ParkRequest
+	(*ParkResponse)(nil),             // 3: parkinglot.v1.ParkResponse
+	(*UnparkRequest)(nil),            // 4: parkinglot.v1.UnparkRequest
+	(*UnparkResponse)(nil),           // 5: parkinglot.v1.UnparkResponse
+	(*AvailableSpotRequest)(nil),     // 6: parkinglot.v1.AvailableSpotRequest
+	(*AvailableSpotResponse)(nil),    // 7: parkinglot.v1.AvailableSpotResponse
+	(*SearchVehicleRequest)(nil),     // 8: parkinglot.v1.SearchVehicleRequest
+	(*SearchVehicleResponse)(nil),    // 9: parkinglot.v1.SearchVehicleResponse
+	(*WatchAvailabilityRequest)(nil), // 10: parkinglot.v1.WatchAvailabilityRequest
+	(*AvailabilityUpdate)(nil),       // 11: parkinglot.v1.AvailabilityUpdate
+	(*timestamppb.Timestamp)(nil),    // 12: google.protobuf.Timestamp
+}
+var file_parkinglot_proto_depIdxs = []int32{
+	12, // 0: parkinglot.v1.Ticket.entry_time:type_name -> google.protobuf.Timestamp
+	12, // 1: parkinglot.v1.Ticket.exit_time:type_name -> google.protobuf.Timestamp
+	0,  // 2: parkinglot.v1.ParkResponse.ticket:type_name -> parkinglot.v1.Ticket
+	0,  // 3: parkinglot.v1.UnparkResponse.ticket:type_name -> parkinglot.v1.Ticket
+	1,  // 4: parkinglot.v1.UnparkResponse.fee:type_name -> parkinglot.v1.Fee
+	0,  // 5: parkinglot.v1.SearchVehicleResponse.ticket:type_name -> parkinglot.v1.Ticket
+	7,  // 6: parkinglot.v1.AvailabilityUpdate.availability:type_name -> parkinglot.v1.AvailableSpotResponse
+	2,  // 7: parkinglot.v1.ParkingLot.Park:input_type -> parkinglot.v1.ParkRequest
+	4,  // 8: parkinglot.v1.ParkingLot.Unpark:input_type -> parkinglot.v1.UnparkRequest
+	6,  // 9: parkinglot.v1.ParkingLot.AvailableSpot:input_type -> parkinglot.v1.AvailableSpotRequest
+	8,  // 10: parkinglot.v1.ParkingLot.SearchVehicle:input_type -> parkinglot.v1.SearchVehicleRequest
+	10, // 11: parkinglot.v1.ParkingLot.WatchAvailability:input_type -> parkinglot.v1.WatchAvailabilityRequest
+	3,  // 12: parkinglot.v1.ParkingLot.Park:output_type -> parkinglot.v1.ParkResponse
+	5,  // 13: parkinglot.v1.ParkingLot.Unpark:output_type -> parkinglot.v1.UnparkResponse
+	7,  // 14: parkinglot.v1.ParkingLot.AvailableSpot:output_type -> parkinglot.v1.AvailableSpotResponse
+	9,  // 15: parkinglot.v1.ParkingLot.SearchVehicle:output_type -> parkinglot.v1.SearchVehicleResponse
+	11, // 16: parkinglot.v1.ParkingLot.WatchAvailability:output_type -> parkinglot.v1.AvailabilityUpdate
+	12, // [12:17] is the sub-list for method output_type
+	7,  // [7:12] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_parkinglot_proto_init() }
+func file_parkinglot_proto_init() {
+	if File_parkinglot_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_parkinglot_proto_rawDesc), len(file_parkinglot_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_parkinglot_proto_goTypes,
+		DependencyIndexes: file_parkinglot_proto_depIdxs,
+		MessageInfos:      file_parkinglot_proto_msgTypes,
+	}.Build()
+	File_parkinglot_proto = out.File
+	file_parkinglot_proto_goTypes = nil
+	file_parkinglot_proto_depIdxs = nil
+}
@@ -0,0 +1,285 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: parkinglot.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ParkingLot_Park_FullMethodName              = "/parkinglot.v1.ParkingLot/Park"
+	ParkingLot_Unpark_FullMethodName            = "/parkinglot.v1.ParkingLot/Unpark"
+	ParkingLot_AvailableSpot_FullMethodName     = "/parkinglot.v1.ParkingLot/AvailableSpot"
+	ParkingLot_SearchVehicle_FullMethodName     = "/parkinglot.v1.ParkingLot/SearchVehicle"
+	ParkingLot_WatchAvailability_FullMethodName = "/parkinglot.v1.ParkingLot/WatchAvailability"
+)
+
+// ParkingLotClient is the client API for ParkingLot service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ParkingLot mirrors usecases.ParkinglotUsecase for gate controllers.
+type ParkingLotClient interface {
+	Park(ctx context.Context, in *ParkRequest, opts ...grpc.CallOption) (*ParkResponse, error)
+	Unpark(ctx context.Context, in *UnparkRequest, opts ...grpc.CallOption) (*UnparkResponse, error)
+	AvailableSpot(ctx context.Context, in *AvailableSpotRequest, opts ...grpc.CallOption) (*AvailableSpotResponse, error)
+	SearchVehicle(ctx context.Context, in *SearchVehicleRequest, opts ...grpc.CallOption) (*SearchVehicleResponse, error)
+	// WatchAvailability sends the current free spot count of every requested vehicle type,
+	// then an update whenever one of them changes.
+	WatchAvailability(ctx context.Context, in *WatchAvailabilityRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AvailabilityUpdate], error)
+}
+
+type parkingLotClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewParkingLotClient(cc grpc.ClientConnInterface) ParkingLotClient {
+	return &parkingLotClient{cc}
+}
+
+func (c *parkingLotClient) Park(ctx context.Context, in *ParkRequest, opts ...grpc.CallOption) (*ParkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParkResponse)
+	err := c.cc.Invoke(ctx, ParkingLot_Park_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingLotClient) Unpark(ctx context.Context, in *UnparkRequest, opts ...grpc.CallOption) (*UnparkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnparkResponse)
+	err := c.cc.Invoke(ctx, ParkingLot_Unpark_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingLotClient) AvailableSpot(ctx context.Context, in *AvailableSpotRequest, opts ...grpc.CallOption) (*AvailableSpotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AvailableSpotResponse)
+	err := c.cc.Invoke(ctx, ParkingLot_AvailableSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingLotClient) SearchVehicle(ctx context.Context, in *SearchVehicleRequest, opts ...grpc.CallOption) (*SearchVehicleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchVehicleResponse)
+	err := c.cc.Invoke(ctx, ParkingLot_SearchVehicle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingLotClient) WatchAvailability(ctx context.Context, in *WatchAvailabilityRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AvailabilityUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ParkingLot_ServiceDesc.Streams[0], ParkingLot_WatchAvailability_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAvailabilityRequest, AvailabilityUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ParkingLot_WatchAvailabilityClient = grpc.ServerStreamingClient[AvailabilityUpdate]
+
+// ParkingLotServer is the server API for ParkingLot service.
+// All implementations must embed UnimplementedParkingLotServer
+// for forward compatibility.
+//
+// ParkingLot mirrors usecases.ParkinglotUsecase for gate controllers.
+type ParkingLotServer interface {
+	Park(context.Context, *ParkRequest) (*ParkResponse, error)
+	Unpark(context.Context, *UnparkRequest) (*UnparkResponse, error)
+	AvailableSpot(context.Context, *AvailableSpotRequest) (*AvailableSpotResponse, error)
+	SearchVehicle(context.Context, *SearchVehicleRequest) (*SearchVehicleResponse, error)
+	// WatchAvailability sends the current free spot count of every requested vehicle type,
+	// then an update whenever one of them changes.
+	WatchAvailability(*WatchAvailabilityRequest, grpc.ServerStreamingServer[AvailabilityUpdate]) error
+	mustEmbedUnimplementedParkingLotServer()
+}
+
+// UnimplementedParkingLotServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedParkingLotServer struct{}
+
+func (UnimplementedParkingLotServer) Park(context.Context, *ParkRequest) (*ParkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Park not implemented")
+}
+func (UnimplementedParkingLotServer) Unpark(context.Context, *UnparkRequest) (*UnparkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unpark not implemented")
+}
+func (UnimplementedParkingLotServer) AvailableSpot(context.Context, *AvailableSpotRequest) (*AvailableSpotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AvailableSpot not implemented")
+}
+func (UnimplementedParkingLotServer) SearchVehicle(context.Context, *SearchVehicleRequest) (*SearchVehicleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchVehicle not implemented")
+}
+func (UnimplementedParkingLotServer) WatchAvailability(*WatchAvailabilityRequest, grpc.ServerStreamingServer[AvailabilityUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAvailability not implemented")
+}
+func (UnimplementedParkingLotServer) mustEmbedUnimplementedParkingLotServer() {}
+func (UnimplementedParkingLotServer) testEmbeddedByValue()                    {}
+
+// UnsafeParkingLotServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ParkingLotServer will
+// result in compilation errors.
+type UnsafeParkingLotServer interface {
+	mustEmbedUnimplementedParkingLotServer()
+}
+
+func RegisterParkingLotServer(s grpc.ServiceRegistrar, srv ParkingLotServer) {
+	// If the following call pancis, it indicates UnimplementedParkingLotServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ParkingLot_ServiceDesc, srv)
+}
+
+func _ParkingLot_Park_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingLotServer).Park(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingLot_Park_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingLotServer).Park(ctx, req.(*ParkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingLot_Unpark_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnparkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingLotServer).Unpark(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingLot_Unpark_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingLotServer).Unpark(ctx, req.(*UnparkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingLot_AvailableSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AvailableSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingLotServer).AvailableSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingLot_AvailableSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingLotServer).AvailableSpot(ctx, req.(*AvailableSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingLot_SearchVehicle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchVehicleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingLotServer).SearchVehicle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingLot_SearchVehicle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingLotServer).SearchVehicle(ctx, req.(*SearchVehicleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingLot_WatchAvailability_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAvailabilityRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ParkingLotServer).WatchAvailability(m, &grpc.GenericServerStream[WatchAvailabilityRequest, AvailabilityUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ParkingLot_WatchAvailabilityServer = grpc.ServerStreamingServer[AvailabilityUpdate]
+
+// ParkingLot_ServiceDesc is the grpc.ServiceDesc for ParkingLot service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ParkingLot_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "parkinglot.v1.ParkingLot",
+	HandlerType: (*ParkingLotServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Park",
+			Handler:    _ParkingLot_Park_Handler,
+		},
+		{
+			MethodName: "Unpark",
+			Handler:    _ParkingLot_Unpark_Handler,
+		},
+		{
+			MethodName: "AvailableSpot",
+			Handler:    _ParkingLot_AvailableSpot_Handler,
+		},
+		{
+			MethodName: "SearchVehicle",
+			Handler:    _ParkingLot_SearchVehicle_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAvailability",
+			Handler:       _ParkingLot_WatchAvailability_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "parkinglot.proto",
+}
@@ -0,0 +1,207 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"submit_do_it/constants"
+	"submit_do_it/delivery/grpcapi/pb"
+	"submit_do_it/domain"
+	"submit_do_it/usecases"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the ParkingLot gRPC service on top of a ParkinglotUsecase.
+type Server struct {
+	pb.UnimplementedParkingLotServer
+
+	usecase usecases.ParkinglotUsecase
+
+	// WatchInterval is how often WatchAvailability checks for changes.
+	WatchInterval time.Duration
+}
+
+func NewServer(usecase usecases.ParkinglotUsecase) *Server {
+	return &Server{
+		usecase:       usecase,
+		WatchInterval: time.Second,
+	}
+}
+
+func (s *Server) Park(ctx context.Context, req *pb.ParkRequest) (*pb.ParkResponse, error) {
+	vt, err := vehicleType(req.GetVehicleType())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetVehicleNumber() == "" {
+		return nil, status.Error(codes.InvalidArgument, "vehicle_number is required")
+	}
+
+	ticket, err := s.usecase.Park(vt, req.GetVehicleNumber())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ParkResponse{Ticket: toTicket(ticket, s.usecase.SpotLabel)}, nil
+}
+
+func (s *Server) Unpark(ctx context.Context, req *pb.UnparkRequest) (*pb.UnparkResponse, error) {
+	var (
+		receipt domain.Receipt
+		err     error
+	)
+	switch {
+	case req.GetTicketId() != "":
+		receipt, err = s.usecase.UnparkTicket(req.GetTicketId())
+	case req.GetSpotId() != "" && req.GetVehicleNumber() != "":
+		receipt, err = s.usecase.Unpark(req.GetSpotId(), req.GetVehicleNumber())
+	default:
+		return nil, status.Error(codes.InvalidArgument, "ticket_id, or spot_id and vehicle_number, are required")
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.UnparkResponse{
+		Ticket: toTicket(receipt.Ticket, s.usecase.SpotLabel),
+		Fee:    &pb.Fee{Amount: receipt.Fee.Amount, Currency: receipt.Fee.Currency},
+	}, nil
+}
+
+func (s *Server) AvailableSpot(ctx context.Context, req *pb.AvailableSpotRequest) (*pb.AvailableSpotResponse, error) {
+	vt, err := vehicleType(req.GetVehicleType())
+	if err != nil {
+		return nil, err
+	}
+	return s.availability(vt), nil
+}
+
+func (s *Server) SearchVehicle(ctx context.Context, req *pb.SearchVehicleRequest) (*pb.SearchVehicleResponse, error) {
+	location, err := s.usecase.SearchVehicle(req.GetVehicleNumber())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.SearchVehicleResponse{
+		SpotId:    s.usecase.SpotLabel(location.SpotID),
+		SpotRange: s.usecase.SpotLabel(location.SpotRange),
+		Parked:    location.Parked,
+		Ticket:    toTicket(location.Ticket, s.usecase.SpotLabel),
+	}, nil
+}
+
+// WatchAvailability polls the usecase every WatchInterval and streams the counts whenever
+// they differ from what was last sent, so changes made through any entrypoint are seen.
+func (s *Server) WatchAvailability(req *pb.WatchAvailabilityRequest, stream pb.ParkingLot_WatchAvailabilityServer) error {
+	types := constants.Types()
+	if len(req.GetVehicleTypes()) > 0 {
+		types = make([]constants.VehicleType, len(req.GetVehicleTypes()))
+		for i, name := range req.GetVehicleTypes() {
+			vt, err := vehicleType(name)
+			if err != nil {
+				return err
+			}
+			types[i] = vt
+		}
+	}
+
+	ticker := time.NewTicker(s.WatchInterval)
+	defer ticker.Stop()
+
+	var last []int32
+	for {
+		update := &pb.AvailabilityUpdate{}
+		current := make([]int32, len(types))
+		for i, vt := range types {
+			a := s.availability(vt)
+			update.Availability = append(update.Availability, a)
+			current[i] = a.GetAvailable()
+		}
+
+		if !equalCounts(last, current) {
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) availability(vt constants.VehicleType) *pb.AvailableSpotResponse {
+	return &pb.AvailableSpotResponse{
+		VehicleType: string(vt),
+		Available:   int32(s.usecase.AvailableSpot(vt)),
+	}
+}
+
+func equalCounts(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func vehicleType(s string) (constants.VehicleType, error) {
+	for _, vt := range constants.Types() {
+		if string(vt) == s {
+			return vt, nil
+		}
+	}
+	return "", toStatus(fmt.Errorf("%w %q", usecases.ErrUnknownVehicleType, s))
+}
+
+// toTicket converts t, writing its spot IDs through label.
+func toTicket(t domain.Ticket, label func(string) string) *pb.Ticket {
+	ticket := &pb.Ticket{
+		Id:            t.ID,
+		VehicleNumber: t.VehicleNumber,
+		VehicleType:   string(t.VehicleType),
+		SpotId:        label(t.SpotID),
+		SpotType:      string(t.ParkedSpotType()),
+		SpotRange:     label(t.SpotRange()),
+		EntryTime:     timestamppb.New(t.EntryTime),
+		PaymentId:     t.PaymentID,
+		Refunded:      t.Refunded,
+	}
+	if !t.Active() {
+		ticket.ExitTime = timestamppb.New(t.ExitTime)
+	}
+	return ticket
+}
+
+// toStatus maps usecase errors to gRPC status codes.
+func toStatus(err error) error {
+	var code codes.Code
+	switch usecases.KindOf(err) {
+	case usecases.KindInvalid:
+		code = codes.InvalidArgument
+	case usecases.KindNotFound:
+		code = codes.NotFound
+	case usecases.KindAlreadyExists:
+		code = codes.AlreadyExists
+	case usecases.KindExhausted:
+		code = codes.ResourceExhausted
+	case usecases.KindFailedPrecondition, usecases.KindPaymentRequired:
+		code = codes.FailedPrecondition
+	default:
+		code = codes.Internal
+	}
+
+	st := status.New(code, err.Error())
+	if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: usecases.CodeOf(err), Domain: "parkinglot"}); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
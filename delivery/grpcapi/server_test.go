@@ -0,0 +1,192 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/delivery/grpcapi/pb"
+	"submit_do_it/usecases"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves u over an in-memory bufconn listener and returns a connected client.
+func newTestClient(t *testing.T, u usecases.ParkinglotUsecase) pb.ParkingLotClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+
+	srv := NewServer(u)
+	srv.WatchInterval = 10 * time.Millisecond
+	g := grpc.NewServer()
+	pb.RegisterParkingLotServer(g, srv)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewParkingLotClient(conn)
+}
+
+func newTestLot() usecases.ParkinglotUsecase {
+	return usecases.NewParkingLotUsecase(1, 1, 3, [][]string{{"B-1", "A-1", "A-1"}})
+}
+
+func TestServer_ParkUnpark(t *testing.T) {
+	client := newTestClient(t, newTestLot())
+	ctx := context.Background()
+
+	parked, err := client.Park(ctx, &pb.ParkRequest{VehicleType: "A", VehicleNumber: "CAR123"})
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	ticket := parked.GetTicket()
+	if ticket.GetSpotId() != "0-0-1" || ticket.GetId() == "" || ticket.GetExitTime() != nil {
+		t.Errorf("unexpected ticket: %v", ticket)
+	}
+
+	_, err = client.Park(ctx, &pb.ParkRequest{VehicleType: "A", VehicleNumber: "CAR123"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("park twice: got %v, want AlreadyExists", err)
+	}
+
+	avail, err := client.AvailableSpot(ctx, &pb.AvailableSpotRequest{VehicleType: "A"})
+	if err != nil || avail.GetAvailable() != 1 {
+		t.Errorf("AvailableSpot = %v, %v; want 1", avail, err)
+	}
+
+	found, err := client.SearchVehicle(ctx, &pb.SearchVehicleRequest{VehicleNumber: "CAR123"})
+	if err != nil || !found.GetParked() || found.GetSpotId() != "0-0-1" {
+		t.Errorf("SearchVehicle = %v, %v", found, err)
+	}
+
+	unparked, err := client.Unpark(ctx, &pb.UnparkRequest{TicketId: ticket.GetId()})
+	if err != nil {
+		t.Fatalf("Unpark failed: %v", err)
+	}
+	if unparked.GetTicket().GetExitTime() == nil || unparked.GetFee().GetAmount() != 0 {
+		t.Errorf("unexpected unpark response: %v", unparked)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	client := newTestClient(t, newTestLot())
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		call   func() error
+		code   codes.Code
+		reason string // ErrorInfo reason, if the error came from the usecase
+	}{
+		{"UnknownVehicleType", func() error {
+			_, err := client.Park(ctx, &pb.ParkRequest{VehicleType: "Z", VehicleNumber: "X"})
+			return err
+		}, codes.InvalidArgument, "unknown_vehicle_type"},
+		{"MissingVehicleNumber", func() error {
+			_, err := client.Park(ctx, &pb.ParkRequest{VehicleType: "A"})
+			return err
+		}, codes.InvalidArgument, ""},
+		{"LotFull", func() error {
+			client.Park(ctx, &pb.ParkRequest{VehicleType: "B", VehicleNumber: "BIKE1"})
+			_, err := client.Park(ctx, &pb.ParkRequest{VehicleType: "B", VehicleNumber: "BIKE2"})
+			return err
+		}, codes.ResourceExhausted, "lot_full"},
+		{"AlreadyParked", func() error {
+			_, err := client.Park(ctx, &pb.ParkRequest{VehicleType: "B", VehicleNumber: "BIKE1"})
+			return err
+		}, codes.AlreadyExists, "already_parked"},
+		{"UnparkWithoutTarget", func() error {
+			_, err := client.Unpark(ctx, &pb.UnparkRequest{})
+			return err
+		}, codes.InvalidArgument, ""},
+		{"UnparkInvalidSpotID", func() error {
+			_, err := client.Unpark(ctx, &pb.UnparkRequest{SpotId: "nope", VehicleNumber: "BIKE1"})
+			return err
+		}, codes.InvalidArgument, "invalid_spot_id"},
+		{"UnparkWrongSpot", func() error {
+			_, err := client.Unpark(ctx, &pb.UnparkRequest{SpotId: "0-0-2", VehicleNumber: "BIKE1"})
+			return err
+		}, codes.NotFound, "vehicle_mismatch"},
+		{"UnknownVehicle", func() error {
+			_, err := client.SearchVehicle(ctx, &pb.SearchVehicleRequest{VehicleNumber: "NOPE"})
+			return err
+		}, codes.NotFound, "vehicle_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if status.Code(err) != tt.code {
+				t.Fatalf("got %v, want %s", err, tt.code)
+			}
+			if tt.reason == "" {
+				return
+			}
+			var reason string
+			for _, d := range status.Convert(err).Details() {
+				if info, ok := d.(*errdetails.ErrorInfo); ok {
+					reason = info.GetReason()
+				}
+			}
+			if reason != tt.reason {
+				t.Errorf("reason = %q, want %q", reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestServer_WatchAvailability(t *testing.T) {
+	u := newTestLot()
+	client := newTestClient(t, u)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchAvailability(ctx, &pb.WatchAvailabilityRequest{VehicleTypes: []string{"A"}})
+	if err != nil {
+		t.Fatalf("WatchAvailability failed: %v", err)
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if got := first.GetAvailability(); len(got) != 1 || got[0].GetAvailable() != 2 {
+		t.Fatalf("initial update = %v, want 2 free A spots", got)
+	}
+
+	// Changes made directly on the usecase are picked up too.
+	if _, err := u.Park(constants.Automobile, "CAR123"); err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	next, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if got := next.GetAvailability()[0].GetAvailable(); got != 1 {
+		t.Errorf("update after park = %d, want 1", got)
+	}
+
+	bad, err := client.WatchAvailability(ctx, &pb.WatchAvailabilityRequest{VehicleTypes: []string{"Z"}})
+	if err != nil {
+		t.Fatalf("WatchAvailability failed: %v", err)
+	}
+	if _, err := bad.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("watch unknown type: got %v, want InvalidArgument", err)
+	}
+}
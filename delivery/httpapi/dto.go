@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"submit_do_it/domain"
+	"submit_do_it/usecases"
+	"time"
+)
+
+type parkRequest struct {
+	VehicleType   string `json:"vehicle_type"`
+	VehicleNumber string `json:"vehicle_number"`
+}
+
+type unparkRequest struct {
+	TicketID      string `json:"ticket_id,omitempty"`
+	SpotID        string `json:"spot_id,omitempty"`
+	VehicleNumber string `json:"vehicle_number,omitempty"`
+}
+
+type reserveRequest struct {
+	VehicleType   string    `json:"vehicle_type"`
+	VehicleNumber string    `json:"vehicle_number"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+}
+
+type waitlistRequest struct {
+	VehicleType   string `json:"vehicle_type"`
+	VehicleNumber string `json:"vehicle_number"`
+	Priority      int    `json:"priority,omitempty"`
+}
+
+type ticketResponse struct {
+	ID            string     `json:"id"`
+	VehicleNumber string     `json:"vehicle_number"`
+	VehicleType   string     `json:"vehicle_type"`
+	SpotID        string     `json:"spot_id"`
+	SpotType      string     `json:"spot_type"`
+	SpotRange     string     `json:"spot_range"`
+	EntryTime     time.Time  `json:"entry_time"`
+	ExitTime      *time.Time `json:"exit_time,omitempty"`
+	DurationSec   int64      `json:"duration_seconds,omitempty"`
+	PaymentID     string     `json:"payment_id,omitempty"`
+	Refunded      bool       `json:"refunded,omitempty"`
+	ReservationID string     `json:"reservation_id,omitempty"`
+}
+
+// The new*Response functions write spot IDs through label, so a lot with spot labels
+// answers with the names on its signs.
+func newTicketResponse(t domain.Ticket, label func(string) string) ticketResponse {
+	resp := ticketResponse{
+		ID:            t.ID,
+		VehicleNumber: t.VehicleNumber,
+		VehicleType:   string(t.VehicleType),
+		SpotID:        label(t.SpotID),
+		SpotType:      string(t.ParkedSpotType()),
+		SpotRange:     label(t.SpotRange()),
+		EntryTime:     t.EntryTime,
+		PaymentID:     t.PaymentID,
+		Refunded:      t.Refunded,
+		ReservationID: t.ReservationID,
+	}
+	if !t.Active() {
+		exit := t.ExitTime
+		resp.ExitTime = &exit
+		resp.DurationSec = int64(t.Duration() / time.Second)
+	}
+	return resp
+}
+
+type feeResponse struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
+
+type receiptResponse struct {
+	Ticket ticketResponse `json:"ticket"`
+	Fee    feeResponse    `json:"fee"`
+}
+
+func newReceiptResponse(r domain.Receipt, label func(string) string) receiptResponse {
+	return receiptResponse{
+		Ticket: newTicketResponse(r.Ticket, label),
+		Fee:    feeResponse{Amount: r.Fee.Amount, Currency: r.Fee.Currency},
+	}
+}
+
+type availabilityResponse struct {
+	VehicleType string `json:"vehicle_type"`
+	Available   int    `json:"available"`
+}
+
+type locationResponse struct {
+	SpotID    string         `json:"spot_id"`
+	SpotRange string         `json:"spot_range"`
+	Parked    bool           `json:"parked"`
+	Ticket    ticketResponse `json:"ticket"`
+}
+
+type reservationResponse struct {
+	ID            string    `json:"id"`
+	VehicleNumber string    `json:"vehicle_number"`
+	VehicleType   string    `json:"vehicle_type"`
+	SpotType      string    `json:"spot_type"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Status        string    `json:"status"`
+	SpotID        string    `json:"spot_id,omitempty"` // set once the spot is held
+}
+
+func newReservationResponse(r domain.Reservation, label func(string) string) reservationResponse {
+	return reservationResponse{
+		ID:            r.ID,
+		VehicleNumber: r.VehicleNumber,
+		VehicleType:   string(r.VehicleType),
+		SpotType:      string(r.SpotType),
+		Start:         r.Start,
+		End:           r.End,
+		Status:        string(r.Status),
+		SpotID:        label(r.SpotID),
+	}
+}
+
+// waitlistResponse is the place of a vehicle in line, or the reservation holding the spot
+// offered to it.
+type waitlistResponse struct {
+	Position    int                  `json:"position"`
+	Reservation *reservationResponse `json:"reservation,omitempty"`
+}
+
+type activationResponse struct {
+	Spots    []string `json:"spots"`
+	Occupied []string `json:"occupied"`
+}
+
+func newActivationResponse(r usecases.ActivationResult, label func(string) string) activationResponse {
+	resp := activationResponse{Spots: make([]string, len(r.Spots)), Occupied: make([]string, len(r.Occupied))}
+	for i, id := range r.Spots {
+		resp.Spots[i] = label(id)
+	}
+	for i, id := range r.Occupied {
+		resp.Occupied[i] = label(id)
+	}
+	return resp
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"` // usecases.CodeOf, or "invalid_request"
+}
@@ -0,0 +1,362 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/layout"
+	"submit_do_it/usecases"
+	"time"
+)
+
+// Server exposes a ParkinglotUsecase as a JSON API.
+//
+//	POST /park                       {"vehicle_type": "A", "vehicle_number": "CAR123"}
+//	POST /unpark                     {"ticket_id": "..."} or {"spot_id": "0-1-2", "vehicle_number": "CAR123"}
+//	POST /tickets/{id}/refund
+//	GET  /availability               free spots for every vehicle type
+//	GET  /availability/{type}
+//	GET  /vehicles/{number}          current or last location
+//	GET  /vehicles/{number}/history
+//	GET  /layout                     lot layout as JSON, or ASCII with ?format=ascii
+//	POST /spots/{selector}/deactivate
+//	POST /spots/{selector}/reactivate
+//	POST /reservations               {"vehicle_type": "A", "vehicle_number": "CAR123", "start": "...", "end": "..."}
+//	GET  /reservations/{id}          open reservation
+//	DELETE /reservations/{id}        cancel
+//	POST /waitlist                   {"vehicle_type": "A", "vehicle_number": "CAR123", "priority": 0}
+//	GET  /waitlist/{number}          place in line, or the reservation offered since
+//	DELETE /waitlist/{number}        leave the waitlist
+type Server struct {
+	usecase usecases.ParkinglotUsecase
+	mux     *http.ServeMux
+}
+
+func NewServer(usecase usecases.ParkinglotUsecase) *Server {
+	s := &Server{
+		usecase: usecase,
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /park", s.park)
+	s.mux.HandleFunc("POST /unpark", s.unpark)
+	s.mux.HandleFunc("POST /tickets/{id}/refund", s.refund)
+	s.mux.HandleFunc("GET /availability", s.availability)
+	s.mux.HandleFunc("GET /availability/{type}", s.availabilityByType)
+	s.mux.HandleFunc("GET /vehicles/{number}", s.searchVehicle)
+	s.mux.HandleFunc("GET /vehicles/{number}/history", s.vehicleHistory)
+	s.mux.HandleFunc("GET /layout", s.layout)
+	s.mux.HandleFunc("POST /spots/{selector}/deactivate", s.deactivate)
+	s.mux.HandleFunc("POST /spots/{selector}/reactivate", s.reactivate)
+	s.mux.HandleFunc("POST /reservations", s.reserve)
+	s.mux.HandleFunc("GET /reservations/{id}", s.reservation)
+	s.mux.HandleFunc("DELETE /reservations/{id}", s.cancelReservation)
+	s.mux.HandleFunc("POST /waitlist", s.joinWaitlist)
+	s.mux.HandleFunc("GET /waitlist/{number}", s.waitlistEntry)
+	s.mux.HandleFunc("DELETE /waitlist/{number}", s.leaveWaitlist)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, then stops accepting connections
+// and waits up to shutdownTimeout for in-flight requests to finish.
+func (s *Server) ListenAndServe(ctx context.Context, addr string, shutdownTimeout time.Duration) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) park(w http.ResponseWriter, r *http.Request) {
+	var req parkRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.VehicleType == "" || req.VehicleNumber == "" {
+		writeError(w, http.StatusBadRequest, "vehicle_type and vehicle_number are required")
+		return
+	}
+
+	ticket, err := s.usecase.Park(constants.VehicleType(req.VehicleType), req.VehicleNumber)
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newTicketResponse(ticket, s.usecase.SpotLabel))
+}
+
+func (s *Server) unpark(w http.ResponseWriter, r *http.Request) {
+	var req unparkRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	var (
+		receipt domain.Receipt
+		err     error
+	)
+	switch {
+	case req.TicketID != "":
+		receipt, err = s.usecase.UnparkTicket(req.TicketID)
+	case req.SpotID != "" && req.VehicleNumber != "":
+		receipt, err = s.usecase.Unpark(req.SpotID, req.VehicleNumber)
+	default:
+		writeError(w, http.StatusBadRequest, "ticket_id, or spot_id and vehicle_number, are required")
+		return
+	}
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newReceiptResponse(receipt, s.usecase.SpotLabel))
+}
+
+func (s *Server) refund(w http.ResponseWriter, r *http.Request) {
+	if err := s.usecase.RefundTicket(r.PathValue("id")); err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deactivate closes a floor ("1"), a row ("1-2") or a spot to new vehicles.
+func (s *Server) deactivate(w http.ResponseWriter, r *http.Request) {
+	result, err := s.usecase.Deactivate(r.PathValue("selector"))
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newActivationResponse(result, s.usecase.SpotLabel))
+}
+
+func (s *Server) reactivate(w http.ResponseWriter, r *http.Request) {
+	result, err := s.usecase.Reactivate(r.PathValue("selector"))
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newActivationResponse(result, s.usecase.SpotLabel))
+}
+
+func (s *Server) reserve(w http.ResponseWriter, r *http.Request) {
+	var req reserveRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.VehicleType == "" || req.VehicleNumber == "" || req.Start.IsZero() || req.End.IsZero() {
+		writeError(w, http.StatusBadRequest, "vehicle_type, vehicle_number, start and end are required")
+		return
+	}
+
+	reservation, err := s.usecase.Reserve(constants.VehicleType(req.VehicleType), req.VehicleNumber, req.Start, req.End)
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newReservationResponse(reservation, s.usecase.SpotLabel))
+}
+
+func (s *Server) reservation(w http.ResponseWriter, r *http.Request) {
+	reservation, err := s.usecase.Reservation(r.PathValue("id"))
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newReservationResponse(reservation, s.usecase.SpotLabel))
+}
+
+func (s *Server) cancelReservation(w http.ResponseWriter, r *http.Request) {
+	if err := s.usecase.CancelReservation(r.PathValue("id")); err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// joinWaitlist queues a vehicle. A spot offered later is held as a reservation the
+// vehicle claims by parking; one free at once is returned with the response.
+func (s *Server) joinWaitlist(w http.ResponseWriter, r *http.Request) {
+	var req waitlistRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.VehicleType == "" || req.VehicleNumber == "" {
+		writeError(w, http.StatusBadRequest, "vehicle_type and vehicle_number are required")
+		return
+	}
+
+	var offered domain.Reservation
+	position, err := s.usecase.JoinWaitlist(constants.VehicleType(req.VehicleType), req.VehicleNumber, req.Priority,
+		func(r domain.Reservation) { offered = r })
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.newWaitlistResponse(position, offered))
+}
+
+// waitlistEntry reports the place of a vehicle in line, or the reservation holding the
+// spot offered to it since it joined.
+func (s *Server) waitlistEntry(w http.ResponseWriter, r *http.Request) {
+	position, offered, err := s.usecase.WaitlistEntry(r.PathValue("number"))
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.newWaitlistResponse(position, offered))
+}
+
+func (s *Server) newWaitlistResponse(position int, offered domain.Reservation) waitlistResponse {
+	resp := waitlistResponse{Position: position}
+	if position == 0 {
+		reservation := newReservationResponse(offered, s.usecase.SpotLabel)
+		resp.Reservation = &reservation
+	}
+	return resp
+}
+
+func (s *Server) leaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	if err := s.usecase.LeaveWaitlist(r.PathValue("number")); err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) availability(w http.ResponseWriter, r *http.Request) {
+	types := constants.Types()
+	resp := make([]availabilityResponse, len(types))
+	for i, vt := range types {
+		resp[i] = availabilityResponse{VehicleType: string(vt), Available: s.usecase.AvailableSpot(vt)}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) availabilityByType(w http.ResponseWriter, r *http.Request) {
+	vt, ok := vehicleType(r.PathValue("type"))
+	if !ok {
+		writeUsecaseError(w, usecases.ErrUnknownVehicleType)
+		return
+	}
+	writeJSON(w, http.StatusOK, availabilityResponse{VehicleType: string(vt), Available: s.usecase.AvailableSpot(vt)})
+}
+
+func (s *Server) searchVehicle(w http.ResponseWriter, r *http.Request) {
+	location, err := s.usecase.SearchVehicle(r.PathValue("number"))
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, locationResponse{
+		SpotID:    s.usecase.SpotLabel(location.SpotID),
+		SpotRange: s.usecase.SpotLabel(location.SpotRange),
+		Parked:    location.Parked,
+		Ticket:    newTicketResponse(location.Ticket, s.usecase.SpotLabel),
+	})
+}
+
+func (s *Server) vehicleHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := s.usecase.VehicleHistory(r.PathValue("number"))
+	if err != nil {
+		writeUsecaseError(w, err)
+		return
+	}
+	resp := make([]ticketResponse, len(history))
+	for i, ticket := range history {
+		resp[i] = newTicketResponse(ticket, s.usecase.SpotLabel)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) layout(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("format") == "ascii" {
+		// Render before writing anything so a failure still gets an error status.
+		var buf bytes.Buffer
+		if err := layout.RenderASCII(&buf, s.usecase.Snapshot()); err != nil {
+			writeUsecaseError(w, fmt.Errorf("render layout: %w", err))
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(buf.Bytes())
+		return
+	}
+	writeJSON(w, http.StatusOK, layout.FromSnapshot(s.usecase.Snapshot()))
+}
+
+func vehicleType(s string) (constants.VehicleType, bool) {
+	for _, vt := range constants.Types() {
+		if string(vt) == s {
+			return vt, true
+		}
+	}
+	return "", false
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message, Code: "invalid_request"})
+}
+
+// writeUsecaseError maps usecase errors to HTTP status codes.
+func writeUsecaseError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusCode(err), errorResponse{Error: err.Error(), Code: usecases.CodeOf(err)})
+}
+
+func statusCode(err error) int {
+	switch usecases.KindOf(err) {
+	case usecases.KindInvalid:
+		return http.StatusBadRequest
+	case usecases.KindNotFound:
+		return http.StatusNotFound
+	case usecases.KindAlreadyExists, usecases.KindExhausted, usecases.KindFailedPrecondition:
+		return http.StatusConflict
+	case usecases.KindPaymentRequired:
+		return http.StatusPaymentRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
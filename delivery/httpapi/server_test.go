@@ -0,0 +1,392 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/payment"
+	"submit_do_it/pricing"
+	"submit_do_it/usecases"
+)
+
+func newTestServer(t *testing.T, opts ...usecases.Option) *httptest.Server {
+	t.Helper()
+	u := usecases.NewParkingLotUsecase(1, 1, 3, [][]string{{"B-1", "M-1", "A-1"}}, opts...)
+	srv := httptest.NewServer(NewServer(u))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func do(t *testing.T, srv *httptest.Server, method, path, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServer_ParkAndUnpark(t *testing.T) {
+	srv := newTestServer(t)
+
+	var ticket ticketResponse
+	if code := do(t, srv, "POST", "/park", `{"vehicle_type":"A","vehicle_number":"CAR123"}`, &ticket); code != http.StatusCreated {
+		t.Fatalf("park: status %d", code)
+	}
+	if ticket.ID == "" || ticket.SpotID != "0-0-2" || ticket.VehicleType != "A" || ticket.SpotType != "A" || ticket.SpotRange != "0-0-2" {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+
+	var errResp errorResponse
+	if code := do(t, srv, "POST", "/park", `{"vehicle_type":"A","vehicle_number":"CAR123"}`, &errResp); code != http.StatusConflict {
+		t.Errorf("park twice: status %d, want 409", code)
+	}
+	if errResp.Code != "already_parked" || !strings.Contains(errResp.Error, "spot 0-0-2") {
+		t.Errorf("unexpected error response %+v", errResp)
+	}
+	if code := do(t, srv, "POST", "/park", `{"vehicle_type":"A","vehicle_number":"CAR456"}`, nil); code != http.StatusConflict {
+		t.Errorf("park in full lot: status %d, want 409", code)
+	}
+
+	var available availabilityResponse
+	do(t, srv, "GET", "/availability/A", "", &available)
+	if available.Available != 0 {
+		t.Errorf("expected no free car spots, got %d", available.Available)
+	}
+
+	var location locationResponse
+	if code := do(t, srv, "GET", "/vehicles/CAR123", "", &location); code != http.StatusOK || !location.Parked || location.SpotID != "0-0-2" {
+		t.Errorf("search: status %d, location %+v", code, location)
+	}
+
+	var receipt receiptResponse
+	if code := do(t, srv, "POST", "/unpark", `{"ticket_id":"`+ticket.ID+`"}`, &receipt); code != http.StatusOK {
+		t.Fatalf("unpark: status %d", code)
+	}
+	if receipt.Ticket.ExitTime == nil {
+		t.Errorf("receipt ticket should be closed: %+v", receipt.Ticket)
+	}
+	if code := do(t, srv, "POST", "/unpark", `{"ticket_id":"`+ticket.ID+`"}`, nil); code != http.StatusConflict {
+		t.Errorf("unpark twice: status %d, want 409", code)
+	}
+
+	var history []ticketResponse
+	if code := do(t, srv, "GET", "/vehicles/CAR123/history", "", &history); code != http.StatusOK || len(history) != 1 {
+		t.Errorf("history: status %d, %d tickets", code, len(history))
+	}
+}
+
+func TestServer_SpotLabels(t *testing.T) {
+	srv := newTestServer(t, usecases.WithSpotLabels(domain.SpotLabels{FloorPrefix: "L", Base: 1}))
+
+	var ticket ticketResponse
+	if code := do(t, srv, "POST", "/park", `{"vehicle_type":"A","vehicle_number":"CAR1"}`, &ticket); code != http.StatusCreated {
+		t.Fatalf("park: status %d", code)
+	}
+	if ticket.SpotID != "L1-A-3" || ticket.SpotRange != "L1-A-3" {
+		t.Errorf("park = %+v, want spot L1-A-3", ticket)
+	}
+	var receipt receiptResponse
+	if code := do(t, srv, "POST", "/unpark", `{"spot_id":"`+ticket.SpotID+`","vehicle_number":"CAR1"}`, &receipt); code != http.StatusOK || receipt.Ticket.SpotID != "L1-A-3" {
+		t.Errorf("unpark by the returned label: status %d, %+v", code, receipt)
+	}
+}
+
+func TestServer_UnparkBySpot(t *testing.T) {
+	srv := newTestServer(t)
+
+	var ticket ticketResponse
+	do(t, srv, "POST", "/park", `{"vehicle_type":"B","vehicle_number":"BIKE123"}`, &ticket)
+
+	if code := do(t, srv, "POST", "/unpark", `{"spot_id":"0-0-1","vehicle_number":"BIKE123"}`, nil); code != http.StatusNotFound {
+		t.Errorf("unpark at wrong spot: status %d, want 404", code)
+	}
+	if code := do(t, srv, "POST", "/unpark", `{"spot_id":"`+ticket.SpotID+`","vehicle_number":"BIKE123"}`, nil); code != http.StatusOK {
+		t.Errorf("unpark: status %d, want 200", code)
+	}
+}
+
+func TestServer_Deactivate(t *testing.T) {
+	srv := newTestServer(t)
+	do(t, srv, "POST", "/park", `{"vehicle_type":"B","vehicle_number":"BIKE123"}`, nil)
+
+	var result activationResponse
+	if code := do(t, srv, "POST", "/spots/0-0/deactivate", "", &result); code != http.StatusOK {
+		t.Fatalf("deactivate: status %d, want 200", code)
+	}
+	if len(result.Spots) != 3 || len(result.Occupied) != 1 || result.Occupied[0] != "0-0-0" {
+		t.Errorf("deactivate = %+v", result)
+	}
+	var avail availabilityResponse
+	do(t, srv, "GET", "/availability/A", "", &avail)
+	if avail.Available != 0 {
+		t.Errorf("A available after deactivate = %d, want 0", avail.Available)
+	}
+
+	if code := do(t, srv, "POST", "/spots/0-0-2/reactivate", "", &result); code != http.StatusOK || len(result.Spots) != 1 {
+		t.Errorf("reactivate: status %d, result %+v", code, result)
+	}
+	var errResp errorResponse
+	if code := do(t, srv, "POST", "/spots/7/deactivate", "", &errResp); code != http.StatusNotFound || errResp.Code != "spot_not_found" {
+		t.Errorf("deactivate missing floor: status %d, %+v", code, errResp)
+	}
+}
+
+func TestServer_Reservations(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	srv := newTestServer(t, usecases.WithClock(func() time.Time { return clock }))
+
+	var reservation reservationResponse
+	body := `{"vehicle_type":"A","vehicle_number":"CAR1","start":"2024-05-01T09:00:00Z","end":"2024-05-01T18:00:00Z"}`
+	if code := do(t, srv, "POST", "/reservations", body, &reservation); code != http.StatusCreated {
+		t.Fatalf("reserve: status %d", code)
+	}
+	if reservation.ID != "R00000001" || reservation.Status != "held" || reservation.SpotID != "0-0-2" {
+		t.Errorf("reserve = %+v", reservation)
+	}
+	var errResp errorResponse
+	if code := do(t, srv, "POST", "/park", `{"vehicle_type":"A","vehicle_number":"CAR2"}`, &errResp); code != http.StatusConflict || errResp.Code != "lot_full" {
+		t.Errorf("walk-in into held spot: status %d, %+v", code, errResp)
+	}
+	if code := do(t, srv, "POST", "/reservations", strings.Replace(body, "18:00", "08:00", 1), &errResp); code != http.StatusBadRequest || errResp.Code != "invalid_reservation" {
+		t.Errorf("reserve empty window: status %d, %+v", code, errResp)
+	}
+
+	if code := do(t, srv, "GET", "/reservations/"+reservation.ID, "", &reservation); code != http.StatusOK || reservation.Status != "held" {
+		t.Errorf("get reservation: status %d, %+v", code, reservation)
+	}
+	if code := do(t, srv, "DELETE", "/reservations/"+reservation.ID, "", nil); code != http.StatusNoContent {
+		t.Errorf("cancel: status %d, want 204", code)
+	}
+	if code := do(t, srv, "GET", "/reservations/"+reservation.ID, "", &errResp); code != http.StatusNotFound || errResp.Code != "reservation_not_found" {
+		t.Errorf("get cancelled reservation: status %d, %+v", code, errResp)
+	}
+}
+
+func TestServer_Waitlist(t *testing.T) {
+	srv := newTestServer(t, usecases.WithWaitlist(usecases.WaitlistFIFO, time.Hour))
+
+	var joined waitlistResponse
+	if code := do(t, srv, "POST", "/waitlist", `{"vehicle_type":"A","vehicle_number":"CAR1"}`, &joined); code != http.StatusCreated {
+		t.Fatalf("join with a free spot: status %d", code)
+	}
+	if joined.Position != 0 || joined.Reservation == nil || joined.Reservation.Status != "held" || joined.Reservation.SpotID != "0-0-2" {
+		t.Errorf("join with a free spot = %+v", joined)
+	}
+	var ticket ticketResponse
+	if code := do(t, srv, "POST", "/park", `{"vehicle_type":"A","vehicle_number":"CAR1"}`, &ticket); code != http.StatusCreated || ticket.ReservationID != joined.Reservation.ID {
+		t.Errorf("park CAR1: status %d, %+v", code, ticket)
+	}
+
+	joined = waitlistResponse{}
+	if code := do(t, srv, "POST", "/waitlist", `{"vehicle_type":"A","vehicle_number":"CAR2","priority":3}`, &joined); code != http.StatusCreated || joined.Position != 1 || joined.Reservation != nil {
+		t.Errorf("join a full lot: status %d, %+v", code, joined)
+	}
+	var errResp errorResponse
+	if code := do(t, srv, "POST", "/waitlist", `{"vehicle_type":"A","vehicle_number":"CAR2"}`, &errResp); code != http.StatusConflict || errResp.Code != "already_waiting" {
+		t.Errorf("join twice: status %d, %+v", code, errResp)
+	}
+	if code := do(t, srv, "DELETE", "/waitlist/CAR2", "", nil); code != http.StatusNoContent {
+		t.Errorf("leave: status %d, want 204", code)
+	}
+	if code := do(t, srv, "DELETE", "/waitlist/CAR2", "", &errResp); code != http.StatusNotFound || errResp.Code != "vehicle_not_found" {
+		t.Errorf("leave twice: status %d, %+v", code, errResp)
+	}
+
+	// A spot freed after joining is held for the vehicle, which finds it by looking itself up.
+	if code := do(t, srv, "POST", "/waitlist", `{"vehicle_type":"A","vehicle_number":"CAR3"}`, nil); code != http.StatusCreated {
+		t.Fatalf("join CAR3: status %d", code)
+	}
+	var entry waitlistResponse
+	if code := do(t, srv, "GET", "/waitlist/CAR3", "", &entry); code != http.StatusOK || entry.Position != 1 || entry.Reservation != nil {
+		t.Errorf("look up CAR3 in line: status %d, %+v", code, entry)
+	}
+	if code := do(t, srv, "POST", "/unpark", `{"ticket_id":"`+ticket.ID+`"}`, nil); code != http.StatusOK {
+		t.Fatalf("unpark CAR1: status %d", code)
+	}
+	entry = waitlistResponse{}
+	if code := do(t, srv, "GET", "/waitlist/CAR3", "", &entry); code != http.StatusOK || entry.Position != 0 || entry.Reservation == nil ||
+		entry.Reservation.Status != "held" || entry.Reservation.SpotID != "0-0-2" {
+		t.Errorf("look up CAR3 after unpark: status %d, %+v", code, entry)
+	}
+	if code := do(t, srv, "GET", "/waitlist/CAR2", "", &errResp); code != http.StatusNotFound || errResp.Code != "vehicle_not_found" {
+		t.Errorf("look up a vehicle that left: status %d, %+v", code, errResp)
+	}
+
+	disabled := newTestServer(t)
+	if code := do(t, disabled, "POST", "/waitlist", `{"vehicle_type":"A","vehicle_number":"CAR1"}`, &errResp); code != http.StatusConflict || errResp.Code != "waitlist_disabled" {
+		t.Errorf("join without a waitlist: status %d, %+v", code, errResp)
+	}
+}
+
+func TestServer_BadRequests(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"MalformedJSON", "POST", "/park", `{`, http.StatusBadRequest},
+		{"UnknownField", "POST", "/park", `{"type":"A"}`, http.StatusBadRequest},
+		{"UnknownVehicleType", "POST", "/park", `{"vehicle_type":"Z","vehicle_number":"X1"}`, http.StatusBadRequest},
+		{"UnparkWithoutTarget", "POST", "/unpark", `{}`, http.StatusBadRequest},
+		{"UnknownTicket", "POST", "/unpark", `{"ticket_id":"nope"}`, http.StatusNotFound},
+		{"InvalidSpotID", "POST", "/unpark", `{"spot_id":"0-x-1","vehicle_number":"X1"}`, http.StatusBadRequest},
+		{"SpotOutsideLot", "POST", "/unpark", `{"spot_id":"3-0-1","vehicle_number":"X1"}`, http.StatusNotFound},
+		{"UnknownVehicle", "GET", "/vehicles/NOPE", "", http.StatusNotFound},
+		{"UnknownAvailabilityType", "GET", "/availability/Z", "", http.StatusBadRequest},
+		{"WaitlistWithoutVehicle", "POST", "/waitlist", `{"vehicle_type":"A"}`, http.StatusBadRequest},
+		{"WrongMethod", "GET", "/park", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := do(t, srv, tt.method, tt.path, tt.body, nil); code != tt.status {
+				t.Errorf("status %d, want %d", code, tt.status)
+			}
+		})
+	}
+}
+
+func TestServer_PaymentDeclined(t *testing.T) {
+	gateway := payment.NewFakeGateway()
+	engine := &pricing.Engine{Currency: "IDR", Tariffs: map[constants.VehicleType]*pricing.Tariff{
+		constants.Automobile: {FlatFee: 5000},
+	}}
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	srv := newTestServer(t,
+		usecases.WithPricer(engine),
+		usecases.WithPaymentGateway(gateway),
+		usecases.WithClock(func() time.Time { clock = clock.Add(time.Minute); return clock }),
+	)
+
+	var ticket ticketResponse
+	do(t, srv, "POST", "/park", `{"vehicle_type":"A","vehicle_number":"CAR123"}`, &ticket)
+
+	gateway.DeclineNext(1)
+	if code := do(t, srv, "POST", "/unpark", `{"ticket_id":"`+ticket.ID+`"}`, nil); code != http.StatusPaymentRequired {
+		t.Errorf("declined unpark: status %d, want 402", code)
+	}
+
+	var receipt receiptResponse
+	if code := do(t, srv, "POST", "/unpark", `{"ticket_id":"`+ticket.ID+`"}`, &receipt); code != http.StatusOK || receipt.Fee.Amount != 5000 {
+		t.Errorf("unpark: status %d, receipt %+v", code, receipt)
+	}
+	if code := do(t, srv, "POST", "/tickets/"+ticket.ID+"/refund", "", nil); code != http.StatusNoContent {
+		t.Errorf("refund: status %d, want 204", code)
+	}
+	if code := do(t, srv, "POST", "/tickets/"+ticket.ID+"/refund", "", nil); code != http.StatusConflict {
+		t.Errorf("second refund: status %d, want 409", code)
+	}
+}
+
+func TestServer_AvailabilityAndLayout(t *testing.T) {
+	srv := newTestServer(t)
+	do(t, srv, "POST", "/park", `{"vehicle_type":"M","vehicle_number":"MOTO1"}`, nil)
+
+	var all []availabilityResponse
+	if code := do(t, srv, "GET", "/availability", "", &all); code != http.StatusOK || len(all) != len(constants.Types()) {
+		t.Fatalf("availability: status %d, %+v", code, all)
+	}
+	for _, a := range all {
+		want := 1
+		if a.VehicleType == string(constants.Motorcycle) {
+			want = 0
+		}
+		if a.Available != want {
+			t.Errorf("availability of %s = %d, want %d", a.VehicleType, a.Available, want)
+		}
+	}
+
+	var lot struct {
+		Floors []struct {
+			Grid [][]string `json:"grid"`
+		} `json:"floors"`
+	}
+	if code := do(t, srv, "GET", "/layout", "", &lot); code != http.StatusOK || lot.Floors[0].Grid[0][1] != "M-1" {
+		t.Errorf("layout: status %d, %+v", code, lot)
+	}
+
+	resp, err := srv.Client().Get(srv.URL + "/layout?format=ascii")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(body); got != "[Floor 0]\nB m A\n" {
+		t.Errorf("ascii layout = %q", got)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	if got := statusCode(errors.New("boom")); got != http.StatusInternalServerError {
+		t.Errorf("unknown errors should map to 500, got %d", got)
+	}
+	if got := statusCode(fmt.Errorf("payment for ticket T1: %w", usecases.ErrPaymentDeclined)); got != http.StatusPaymentRequired {
+		t.Errorf("wrapped ErrPaymentDeclined should map to 402, got %d", got)
+	}
+}
+
+func TestServer_ListenAndServeShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	u := usecases.NewParkingLotUsecase(1, 1, 1, [][]string{{"A-1"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(u).ListenAndServe(ctx, addr, time.Second)
+	}()
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get("http://" + addr + "/availability/A"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("server did not start: %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ListenAndServe returned %v after shutdown", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -0,0 +1,239 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventParked   EventType = "parked"   // Ticket is the newly issued ticket
+	EventUnparked EventType = "unparked" // Ticket is the closed ticket, with fee and payment
+	EventRefunded EventType = "refunded" // Ticket is the refunded ticket
+
+	EventDeactivated EventType = "deactivated" // Spots were closed to new parks
+	EventReactivated EventType = "reactivated" // Spots were opened again
+
+	EventReserved            EventType = "reserved"             // Reservation is the new booking
+	EventReservationHeld     EventType = "reservation_held"     // Reservation holds the spots from its SpotID
+	EventReservationReleased EventType = "reservation_released" // Reservation was cancelled or its vehicle did not show
+
+	EventAborted EventType = "aborted" // Aborted names an earlier event whose change the repository rejected
+)
+
+// Event is one state change of a lot, recorded before it is applied so the lot can be
+// rebuilt by replaying events onto a snapshot. Seq numbers events without gaps.
+type Event struct {
+	Seq    uint64    `json:"seq"`
+	Type   EventType `json:"type"`
+	Time   time.Time `json:"time"`
+	Ticket Ticket    `json:"ticket,omitzero"`
+	Spots  []string  `json:"spots,omitempty"` // IDs of the spots an activation event changed
+
+	Reservation Reservation `json:"reservation,omitzero"`
+	Aborted     uint64      `json:"aborted,omitempty"` // Seq of the event an aborted event cancels
+}
+
+// ApplyEvent replays a recorded event onto the lot. Events at or before EventSeq are
+// already part of the lot and skipped, so a log may overlap the snapshot it is replayed
+// onto; a gap in the sequence is an error. Like RestoreTickets it leaves AvailableSpots
+// to the caller.
+func (pl *ParkingLot) ApplyEvent(e Event) error {
+	if e.Seq <= pl.EventSeq {
+		return nil
+	}
+	if e.Seq != pl.EventSeq+1 {
+		return fmt.Errorf("event %d: expected event %d, events are missing", e.Seq, pl.EventSeq+1)
+	}
+
+	var err error
+	switch e.Type {
+	case EventAborted:
+		// ApplyEvents skips the aborted event itself; there is nothing left to undo.
+	case EventParked:
+		err = pl.applyParked(e.Ticket)
+	case EventUnparked:
+		err = pl.applyUnparked(e.Ticket)
+	case EventRefunded:
+		err = pl.applyRefunded(e.Ticket)
+	case EventDeactivated, EventReactivated:
+		err = pl.applyActivation(e.Spots, e.Type == EventReactivated)
+	case EventReserved:
+		err = pl.applyReserved(e.Reservation)
+	case EventReservationHeld:
+		err = pl.applyReservationHeld(e.Reservation)
+	case EventReservationReleased:
+		err = pl.applyReservationReleased(e.Reservation)
+	default:
+		err = fmt.Errorf("unknown event type %q", e.Type)
+	}
+	if err != nil && e.Reservation.ID != "" {
+		return fmt.Errorf("event %d (%s reservation %q): %w", e.Seq, e.Type, e.Reservation.ID, err)
+	}
+	if err != nil && e.Ticket.ID == "" {
+		return fmt.Errorf("event %d (%s): %w", e.Seq, e.Type, err)
+	}
+	if err != nil {
+		return fmt.Errorf("event %d (%s ticket %q): %w", e.Seq, e.Type, e.Ticket.ID, err)
+	}
+
+	pl.EventSeq = e.Seq
+	return nil
+}
+
+// ApplyEvents replays events in order, skipping every event a later aborted event in the
+// same slice cancels. Their sequence numbers still count, so the log has no gaps.
+func (pl *ParkingLot) ApplyEvents(events []Event) error {
+	aborted := make(map[uint64]bool)
+	for _, e := range events {
+		if e.Type == EventAborted {
+			aborted[e.Aborted] = true
+		}
+	}
+
+	for _, e := range events {
+		if !aborted[e.Seq] || e.Seq <= pl.EventSeq {
+			if err := pl.ApplyEvent(e); err != nil {
+				return err
+			}
+			continue
+		}
+		if e.Seq != pl.EventSeq+1 {
+			return fmt.Errorf("event %d: expected event %d, events are missing", e.Seq, pl.EventSeq+1)
+		}
+		pl.EventSeq = e.Seq
+	}
+	return nil
+}
+
+func (pl *ParkingLot) applyParked(ticket Ticket) error {
+	if !ticket.Active() {
+		return fmt.Errorf("ticket is closed")
+	}
+	n, err := TicketNumber(ticket.ID)
+	if err != nil {
+		return err
+	}
+	var reservation *Reservation
+	if ticket.ReservationID != "" {
+		reservation = pl.Reservations[ticket.ReservationID]
+		if reservation == nil || reservation.Status != ReservationHeld || reservation.SpotID != ticket.SpotID {
+			return fmt.Errorf("reservation %s holds no spot at %s", ticket.ReservationID, ticket.SpotID)
+		}
+	}
+	if err := pl.restoreTicket(&ticket, max(n, pl.TicketSeq)); err != nil {
+		return err
+	}
+	pl.TicketSeq = max(n, pl.TicketSeq)
+	if reservation != nil {
+		pl.ReleaseSpots(reservation)
+		delete(pl.Reservations, reservation.ID)
+	}
+	return nil
+}
+
+func (pl *ParkingLot) applyUnparked(closed Ticket) error {
+	ticket, ok := pl.Tickets[closed.ID]
+	if !ok {
+		return fmt.Errorf("unknown ticket")
+	}
+	if !ticket.Active() {
+		return fmt.Errorf("ticket already closed")
+	}
+	if closed.Active() {
+		return fmt.Errorf("no exit time")
+	}
+
+	spots, err := pl.TicketSpots(ticket)
+	if err != nil {
+		return err
+	}
+	for _, spot := range spots {
+		if !spot.Occupied || spot.VehicleNumber != ticket.VehicleNumber {
+			return fmt.Errorf("spot %s not occupied by %s", spot.ID(), ticket.VehicleNumber)
+		}
+	}
+
+	ticket.ExitTime = closed.ExitTime
+	ticket.Fee = closed.Fee
+	ticket.PaymentID = closed.PaymentID
+	delete(pl.VehicleMap, ticket.VehicleNumber)
+	for _, spot := range spots {
+		spot.Occupied = false
+		spot.VehicleNumber = ""
+	}
+	return nil
+}
+
+func (pl *ParkingLot) applyRefunded(refunded Ticket) error {
+	ticket, ok := pl.Tickets[refunded.ID]
+	if !ok {
+		return fmt.Errorf("unknown ticket")
+	}
+	if ticket.PaymentID == "" || ticket.Refunded {
+		return fmt.Errorf("ticket not refundable")
+	}
+	ticket.Refunded = true
+	return nil
+}
+
+func (pl *ParkingLot) applyReserved(r Reservation) error {
+	if r.Status != ReservationBooked {
+		return fmt.Errorf("new reservation is %s", r.Status)
+	}
+	n, err := ReservationNumber(r.ID)
+	if err != nil {
+		return err
+	}
+	if err := pl.RestoreReservation(r, max(n, pl.ReservationSeq)); err != nil {
+		return err
+	}
+	pl.ReservationSeq = max(n, pl.ReservationSeq)
+	return nil
+}
+
+func (pl *ParkingLot) applyReservationHeld(held Reservation) error {
+	r, ok := pl.Reservations[held.ID]
+	if !ok {
+		return fmt.Errorf("unknown reservation")
+	}
+	if r.Status != ReservationBooked {
+		return fmt.Errorf("reservation is %s", r.Status)
+	}
+	next := *r
+	next.Status, next.SpotID = ReservationHeld, held.SpotID
+	if _, err := pl.HoldSpots(&next); err != nil {
+		return err
+	}
+	*r = next
+	return nil
+}
+
+func (pl *ParkingLot) applyReservationReleased(released Reservation) error {
+	r, ok := pl.Reservations[released.ID]
+	if !ok {
+		return fmt.Errorf("unknown reservation")
+	}
+	if r.Status == ReservationHeld {
+		pl.ReleaseSpots(r)
+	}
+	delete(pl.Reservations, r.ID)
+	return nil
+}
+
+// applyActivation leaves vehicles where they are; a deactivated spot only stops taking
+// new ones.
+func (pl *ParkingLot) applyActivation(spotIDs []string, active bool) error {
+	for _, id := range spotIDs {
+		spot, err := pl.SpotAt(id)
+		if err != nil {
+			return err
+		}
+		if spot.SpotType == "" {
+			return fmt.Errorf("%s is not a parking spot", id)
+		}
+		spot.Active = active
+	}
+	return nil
+}
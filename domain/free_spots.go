@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"container/heap"
+)
+
+// FreeSpots indexes the free spots of one vehicle type with a heap per floor, ordered by
+// less. Add and Remove are O(log n) and Peek is O(1), so allocation stays deterministic
+// and cheap on large lots. It is not safe for concurrent use; ParkingLot.Mutx guards it.
+type FreeSpots struct {
+	floors []*spotHeap
+	count  int
+}
+
+func NewFreeSpots(floors int, less func(a, b *Spot) bool) *FreeSpots {
+	fs := &FreeSpots{floors: make([]*spotHeap, floors)}
+	for f := range fs.floors {
+		fs.floors[f] = &spotHeap{less: less}
+	}
+	return fs
+}
+
+// Len returns the number of free spots on all floors.
+func (fs *FreeSpots) Len() int {
+	if fs == nil {
+		return 0
+	}
+	return fs.count
+}
+
+// Floors returns the number of floors indexed.
+func (fs *FreeSpots) Floors() int {
+	if fs == nil {
+		return 0
+	}
+	return len(fs.floors)
+}
+
+// FloorLen returns the number of free spots on a floor.
+func (fs *FreeSpots) FloorLen(floor int) int {
+	if fs == nil || floor < 0 || floor >= len(fs.floors) {
+		return 0
+	}
+	return len(fs.floors[floor].spots)
+}
+
+// Peek returns the most preferred free spot on a floor, or nil if the floor is full.
+func (fs *FreeSpots) Peek(floor int) *Spot {
+	if fs.FloorLen(floor) == 0 {
+		return nil
+	}
+	return fs.floors[floor].spots[0]
+}
+
+func (fs *FreeSpots) Contains(spot *Spot) bool {
+	if fs == nil || spot.Floor < 0 || spot.Floor >= len(fs.floors) {
+		return false
+	}
+	spots := fs.floors[spot.Floor].spots
+	return spot.freeIdx >= 0 && spot.freeIdx < len(spots) && spots[spot.freeIdx] == spot
+}
+
+// Add marks spot as free. Adding a spot that is already free is a no-op.
+func (fs *FreeSpots) Add(spot *Spot) {
+	if fs.Contains(spot) {
+		return
+	}
+	heap.Push(fs.floors[spot.Floor], spot)
+	fs.count++
+}
+
+// Remove takes spot out of the index, reporting whether it was free.
+func (fs *FreeSpots) Remove(spot *Spot) bool {
+	if !fs.Contains(spot) {
+		return false
+	}
+	heap.Remove(fs.floors[spot.Floor], spot.freeIdx)
+	fs.count--
+	return true
+}
+
+// Spots returns every free spot, floor by floor, in no particular order within a floor.
+func (fs *FreeSpots) Spots() []*Spot {
+	spots := make([]*Spot, 0, fs.Len())
+	for f := 0; f < fs.Floors(); f++ {
+		spots = append(spots, fs.floors[f].spots...)
+	}
+	return spots
+}
+
+type spotHeap struct {
+	spots []*Spot
+	less  func(a, b *Spot) bool
+}
+
+func (h *spotHeap) Len() int           { return len(h.spots) }
+func (h *spotHeap) Less(i, j int) bool { return h.less(h.spots[i], h.spots[j]) }
+
+func (h *spotHeap) Swap(i, j int) {
+	h.spots[i], h.spots[j] = h.spots[j], h.spots[i]
+	h.spots[i].freeIdx = i
+	h.spots[j].freeIdx = j
+}
+
+func (h *spotHeap) Push(x any) {
+	spot := x.(*Spot)
+	spot.freeIdx = len(h.spots)
+	h.spots = append(h.spots, spot)
+}
+
+func (h *spotHeap) Pop() any {
+	n := len(h.spots)
+	spot := h.spots[n-1]
+	h.spots[n-1] = nil
+	h.spots = h.spots[:n-1]
+	spot.freeIdx = -1
+	return spot
+}
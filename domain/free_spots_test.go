@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"testing"
+)
+
+func lessRowCol(a, b *Spot) bool {
+	if a.Row != b.Row {
+		return a.Row < b.Row
+	}
+	return a.Col < b.Col
+}
+
+func TestFreeSpots(t *testing.T) {
+	fs := NewFreeSpots(2, lessRowCol)
+	spots := []*Spot{
+		{Floor: 0, Row: 1, Col: 0},
+		{Floor: 0, Row: 0, Col: 2},
+		{Floor: 0, Row: 0, Col: 1},
+		{Floor: 1, Row: 3, Col: 3},
+	}
+	for _, spot := range spots {
+		fs.Add(spot)
+	}
+	fs.Add(spots[0])
+
+	if fs.Len() != 4 || fs.FloorLen(0) != 3 || fs.FloorLen(1) != 1 {
+		t.Fatalf("unexpected counts: total %d, floor 0 %d, floor 1 %d", fs.Len(), fs.FloorLen(0), fs.FloorLen(1))
+	}
+	if got := fs.Peek(0); got != spots[2] {
+		t.Errorf("Peek(0) = %s, want %s", got.ID(), spots[2].ID())
+	}
+
+	if !fs.Remove(spots[2]) {
+		t.Errorf("Remove should report a free spot")
+	}
+	if fs.Remove(spots[2]) {
+		t.Errorf("Remove should not report a spot twice")
+	}
+	if fs.Contains(spots[2]) {
+		t.Errorf("removed spot should not be contained")
+	}
+	if got := fs.Peek(0); got != spots[1] {
+		t.Errorf("Peek(0) after remove = %s, want %s", got.ID(), spots[1].ID())
+	}
+
+	fs.Remove(spots[3])
+	if got := fs.Peek(1); got != nil {
+		t.Errorf("Peek on empty floor = %v, want nil", got)
+	}
+	if fs.Len() != 2 || len(fs.Spots()) != 2 {
+		t.Errorf("expected 2 free spots, got %d", fs.Len())
+	}
+}
+
+func TestFreeSpots_Nil(t *testing.T) {
+	var fs *FreeSpots
+	if fs.Len() != 0 || fs.Floors() != 0 || fs.Peek(0) != nil || fs.Contains(&Spot{}) {
+		t.Errorf("nil FreeSpots should behave as empty")
+	}
+}
+
+func TestFreeSpots_OrderAfterChurn(t *testing.T) {
+	fs := NewFreeSpots(1, lessRowCol)
+	var spots []*Spot
+	for r := 0; r < 5; r++ {
+		for c := 0; c < 5; c++ {
+			spot := &Spot{Row: r, Col: c}
+			spots = append(spots, spot)
+			fs.Add(spot)
+		}
+	}
+	for i := 0; i < len(spots); i += 3 {
+		fs.Remove(spots[i])
+	}
+	for i := 0; i < len(spots); i += 6 {
+		fs.Add(spots[i])
+	}
+
+	var prev *Spot
+	for fs.Len() > 0 {
+		spot := fs.Peek(0)
+		if prev != nil && lessRowCol(spot, prev) {
+			t.Fatalf("spots out of order: %s after %s", spot.ID(), prev.ID())
+		}
+		fs.Remove(spot)
+		prev = spot
+	}
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"submit_do_it/constants"
+)
+
+// Layout codes for cells that are not parking spots.
+const (
+	EmptyCode  = "."
+	PillarCode = "#"
+)
+
+// CellError describes a single layout code that could not be parsed.
+type CellError struct {
+	Floor  int
+	Row    int
+	Col    int
+	Code   string
+	Reason string
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("floor %d row %d col %d: %s", e.Floor, e.Row, e.Col, e.Reason)
+}
+
+// DimensionError describes a floor or row whose size does not match what was expected.
+// Row is -1 when the number of rows on the floor is wrong.
+type DimensionError struct {
+	Floor int
+	Row   int
+	Want  int
+	Got   int
+}
+
+func (e *DimensionError) Error() string {
+	if e.Row < 0 {
+		return fmt.Sprintf("floor %d: expected %d rows, got %d", e.Floor, e.Want, e.Got)
+	}
+	return fmt.Sprintf("floor %d row %d: expected %d columns, got %d", e.Floor, e.Row, e.Want, e.Got)
+}
+
+// LayoutError aggregates every problem found while parsing a layout.
+type LayoutError struct {
+	Errors []error
+}
+
+func (e *LayoutError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid layout: %d error(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *LayoutError) Unwrap() []error {
+	return e.Errors
+}
+
+// ParseSpotCode parses a layout code such as "B-1" into its spot type and active flag.
+func ParseSpotCode(code string) (constants.VehicleType, bool, error) {
+	parts := strings.Split(code, "-")
+	if len(parts) != 2 {
+		return "", false, fmt.Errorf("malformed code %q, want TYPE-ACTIVE", code)
+	}
+
+	vt := constants.VehicleType(parts[0])
+	if _, ok := constants.Lookup(vt); !ok {
+		return "", false, fmt.Errorf("unknown spot type %q", parts[0])
+	}
+
+	switch parts[1] {
+	case "1":
+		return vt, true, nil
+	case "0":
+		return vt, false, nil
+	default:
+		return "", false, fmt.Errorf("invalid active flag %q, want 0 or 1", parts[1])
+	}
+}
+
+// ParseLayout turns per-floor code templates into spots. EmptyCode and PillarCode cells
+// become inactive spots without a type. Every cell yields a spot so the result is usable
+// even on error: cells that fail to parse become inactive spots, rows whose length differs
+// from the first row of their floor are reported, and all problems are returned together
+// as a *LayoutError.
+func ParseLayout(floorTemplates [][][]string) ([][][]*Spot, error) {
+	var errs []error
+	layout := make([][][]*Spot, len(floorTemplates))
+
+	for f, template := range floorTemplates {
+		layout[f] = make([][]*Spot, len(template))
+		for r, row := range template {
+			if len(row) != len(template[0]) {
+				errs = append(errs, &DimensionError{Floor: f, Row: r, Want: len(template[0]), Got: len(row)})
+			}
+
+			layout[f][r] = make([]*Spot, len(row))
+			for c, code := range row {
+				spot := &Spot{Floor: f, Row: r, Col: c}
+				switch code {
+				case EmptyCode:
+				case PillarCode:
+					spot.Pillar = true
+				default:
+					vt, active, err := ParseSpotCode(code)
+					if err != nil {
+						errs = append(errs, &CellError{Floor: f, Row: r, Col: c, Code: code, Reason: err.Error()})
+					}
+					spot.SpotType = vt
+					spot.Active = active
+				}
+				layout[f][r][c] = spot
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return layout, &LayoutError{Errors: errs}
+	}
+	return layout, nil
+}
+
+// ValidateTemplate checks that a single template shared by every floor is exactly rows x columns.
+func ValidateTemplate(floors, rows, columns int, template [][]string) error {
+	if floors < 0 || rows < 0 || columns < 0 {
+		return fmt.Errorf("invalid lot dimensions %dx%dx%d", floors, rows, columns)
+	}
+
+	var errs []error
+	if len(template) != rows {
+		errs = append(errs, &DimensionError{Floor: 0, Row: -1, Want: rows, Got: len(template)})
+	}
+	for r, row := range template {
+		if len(row) != columns {
+			errs = append(errs, &DimensionError{Floor: 0, Row: r, Want: columns, Got: len(row)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &LayoutError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"submit_do_it/constants"
+)
+
+func TestParseSpotCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		vt      constants.VehicleType
+		active  bool
+		wantErr bool
+	}{
+		{"ActiveBicycle", "B-1", constants.Bicycle, true, false},
+		{"InactiveMotorcycle", "M-0", constants.Motorcycle, false, false},
+		{"ActiveAutomobile", "A-1", constants.Automobile, true, false},
+		{"MissingSeparator", "B", "", false, true},
+		{"TooManyParts", "B-1-1", "", false, true},
+		{"UnknownType", "X-1", "", false, true},
+		{"BadFlag", "A-2", "", false, true},
+		{"Empty", "", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vt, active, err := ParseSpotCode(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSpotCode(%q) error = %v, wantErr %v", tt.code, err, tt.wantErr)
+			}
+			if vt != tt.vt || active != tt.active {
+				t.Errorf("ParseSpotCode(%q) = (%q, %v), want (%q, %v)", tt.code, vt, active, tt.vt, tt.active)
+			}
+		})
+	}
+}
+
+func TestParseLayout_AggregatesErrors(t *testing.T) {
+	floorTemplates := [][][]string{
+		{
+			{"B-1", "B"},
+			{"M-1", "A-1"},
+		},
+		{
+			{"X-1", "A-1"},
+			{"A-1"},
+		},
+	}
+
+	layout, err := ParseLayout(floorTemplates)
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	var layoutErr *LayoutError
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("expected *LayoutError, got %T", err)
+	}
+	if len(layoutErr.Errors) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(layoutErr.Errors), err)
+	}
+
+	var cellErr *CellError
+	if !errors.As(layoutErr.Errors[0], &cellErr) || cellErr.Floor != 0 || cellErr.Row != 0 || cellErr.Col != 1 {
+		t.Errorf("expected cell error at 0-0-1, got %v", layoutErr.Errors[0])
+	}
+	if !errors.As(layoutErr.Errors[1], &cellErr) || cellErr.Floor != 1 || cellErr.Code != "X-1" {
+		t.Errorf("expected cell error for X-1 on floor 1, got %v", layoutErr.Errors[1])
+	}
+	var dimErr *DimensionError
+	if !errors.As(layoutErr.Errors[2], &dimErr) || dimErr.Floor != 1 || dimErr.Row != 1 {
+		t.Errorf("expected dimension error on floor 1 row 1, got %v", layoutErr.Errors[2])
+	}
+	if !strings.Contains(err.Error(), "floor 1 row 0 col 0") {
+		t.Errorf("error message should locate the bad cell, got %q", err.Error())
+	}
+
+	// Bad cells still produce inactive spots.
+	if spot := layout[0][0][1]; spot == nil || spot.Active {
+		t.Errorf("bad cell should yield an inactive spot, got %+v", spot)
+	}
+	if spot := layout[0][1][1]; !spot.Active || spot.SpotType != constants.Automobile {
+		t.Errorf("valid cell parsed incorrectly: %+v", spot)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	template := [][]string{
+		{"B-1", "M-1"},
+		{"A-1"},
+	}
+
+	if err := ValidateTemplate(1, 2, 2, [][]string{{"B-1", "M-1"}, {"A-1", "A-1"}}); err != nil {
+		t.Errorf("expected valid template, got %v", err)
+	}
+	if err := ValidateTemplate(1, 3, 2, template); err == nil {
+		t.Errorf("expected dimension error, got nil")
+	} else if got := len(err.(*LayoutError).Errors); got != 2 {
+		t.Errorf("expected 2 dimension errors, got %d: %v", got, err)
+	}
+	if err := ValidateTemplate(-1, 0, 0, nil); err == nil {
+		t.Errorf("expected error for negative floors, got nil")
+	}
+}
+
+func TestParseLayout_EmptyAndPillar(t *testing.T) {
+	layout, err := ParseLayout([][][]string{{{EmptyCode, PillarCode, "A-1"}}})
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	row := layout[0][0]
+	if row[0].Active || row[0].Pillar || row[0].SpotType != "" {
+		t.Errorf("empty cell parsed incorrectly: %+v", row[0])
+	}
+	if row[1].Active || !row[1].Pillar {
+		t.Errorf("pillar parsed incorrectly: %+v", row[1])
+	}
+	for i, want := range []string{EmptyCode, PillarCode, "A-1"} {
+		if got := row[i].Code(); got != want {
+			t.Errorf("Code() = %q, want %q", got, want)
+		}
+	}
+}
@@ -12,11 +12,15 @@ type Spot struct {
 	Col      int
 	SpotType constants.VehicleType
 	Active   bool
+	Pillar   bool
 
 	VehicleNumber string
 	Occupied      bool
+	HeldFor       string // ID of the reservation keeping the free spot for its vehicle
 
 	Mutx sync.Mutex
+
+	freeIdx int // position in the FreeSpots heap of its floor
 }
 
 type ParkingLot struct {
@@ -26,13 +30,61 @@ type ParkingLot struct {
 	Layout  [][][]*Spot
 
 	VehicleMap     map[string]string
-	LastSpotMap    map[string]string
-	AvailableSpots map[constants.VehicleType]map[string]*Spot
+	History        map[string][]*Ticket // tickets per vehicle, oldest first
+	Tickets        map[string]*Ticket
+	TicketSeq      uint64
+	EventSeq       uint64                  // last event recorded or replayed
+	Reservations   map[string]*Reservation // open reservations by ID
+	ReservationSeq uint64
+	AvailableSpots map[constants.VehicleType]*FreeSpots
 
-	Mutx sync.RWMutex // protects vehicleMap, lastSpotMap, availableSpots
+	Mutx sync.RWMutex // protects vehicleMap, history, tickets, availableSpots
 }
 
 // Returns spot ID as "floor-row-col"
 func (s *Spot) ID() string {
-	return fmt.Sprintf("%d-%d-%d", s.Floor, s.Row, s.Col)
+	return s.SpotID().String()
+}
+
+// Returns the layout code for the spot, e.g. "B-1", or PillarCode/EmptyCode
+func (s *Spot) Code() string {
+	if s.Pillar {
+		return PillarCode
+	}
+	if s.SpotType == "" {
+		return EmptyCode
+	}
+	active := 0
+	if s.Active {
+		active = 1
+	}
+	return fmt.Sprintf("%s-%d", s.SpotType, active)
+}
+
+// SpotAt returns the spot with the given ID, or an error if it is malformed or outside the layout.
+func (pl *ParkingLot) SpotAt(spotID string) (*Spot, error) {
+	id, err := ParseSpotID(spotID)
+	if err != nil {
+		return nil, err
+	}
+	return pl.Spot(id)
+}
+
+// TicketSpots returns the spots a ticket holds, or an error if any is outside the layout
+// or not of the ticket's spot type.
+func (pl *ParkingLot) TicketSpots(ticket *Ticket) ([]*Spot, error) {
+	span, err := ticket.Span()
+	if err != nil {
+		return nil, err
+	}
+	spots, err := pl.SpotsOf(span)
+	if err != nil {
+		return nil, err
+	}
+	for _, spot := range spots {
+		if spot.SpotType != ticket.ParkedSpotType() {
+			return nil, fmt.Errorf("spot type %q does not match spot %s of type %q", ticket.ParkedSpotType(), spot.ID(), spot.SpotType)
+		}
+	}
+	return spots, nil
 }
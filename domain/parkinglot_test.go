@@ -2,6 +2,8 @@ package domain
 
 import (
 	"testing"
+
+	"submit_do_it/constants"
 )
 
 func TestSpotID(t *testing.T) {
@@ -14,7 +16,7 @@ func TestSpotID(t *testing.T) {
 	}{
 		{"AllZeros", 0, 0, 0, "0-0-0"},
 		{"PositiveCase", 2, 3, 4, "2-3-4"},
-		{"NegativeCase", -1, -2, -3, "-1--2--3"},
+		{"NegativeCase", -1, -2, -3, "invalid(-1,-2,-3)"},
 	}
 
 	for _, tt := range tests {
@@ -31,3 +33,22 @@ func TestSpotID(t *testing.T) {
 		})
 	}
 }
+
+func TestSpotCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		spot     *Spot
+		expected string
+	}{
+		{"ActiveBicycle", &Spot{SpotType: constants.Bicycle, Active: true}, "B-1"},
+		{"InactiveAutomobile", &Spot{SpotType: constants.Automobile}, "A-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spot.Code(); got != tt.expected {
+				t.Errorf("Spot.Code() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"submit_do_it/constants"
+	"time"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationBooked    ReservationStatus = "booked"    // waiting for its window, or for a spot to hold
+	ReservationHeld      ReservationStatus = "held"      // SpotID is kept free for the vehicle
+	ReservationFulfilled ReservationStatus = "fulfilled" // the vehicle parked in its held spot
+	ReservationNoShow    ReservationStatus = "no_show"   // released after the vehicle failed to arrive in time
+	ReservationCancelled ReservationStatus = "cancelled"
+)
+
+// Reservation books spots of SpotType for a vehicle between Start and End. When the window
+// opens a spot is picked and held: nobody else may park there until the vehicle arrives
+// or the hold is released.
+type Reservation struct {
+	ID            string                `json:"id"`
+	VehicleNumber string                `json:"vehicle_number"`
+	VehicleType   constants.VehicleType `json:"vehicle_type"`
+	SpotType      constants.VehicleType `json:"spot_type"`
+	SpotCount     int                   `json:"spot_count,omitempty"` // adjacent spots held; 0 means 1
+	Start         time.Time             `json:"start"`
+	End           time.Time             `json:"end"`
+	Status        ReservationStatus     `json:"status"`
+	SpotID        string                `json:"spot_id,omitempty"` // first held spot, set once the hold starts
+	// Waitlisted marks a spot offered to a vehicle from the waitlist. It is held until End,
+	// the end of the claim window, instead of for the grace period.
+	Waitlisted bool `json:"waitlisted,omitempty"`
+}
+
+// ReservationID formats the ID of the n-th reservation made in a lot, e.g. "R00000042".
+func ReservationID(n uint64) string {
+	return fmt.Sprintf("R%08d", n)
+}
+
+// ReservationNumber returns the sequence number in a reservation ID.
+func ReservationNumber(id string) (uint64, error) {
+	var n uint64
+	if _, err := fmt.Sscanf(id, "R%d", &n); err != nil {
+		return 0, fmt.Errorf("malformed reservation ID %q", id)
+	}
+	return n, nil
+}
+
+// Open reports whether the reservation is still waiting for its vehicle.
+func (r *Reservation) Open() bool {
+	return r.Status == ReservationBooked || r.Status == ReservationHeld
+}
+
+// Overlaps reports whether the windows of r and other share any time.
+func (r *Reservation) Overlaps(other *Reservation) bool {
+	return r.Start.Before(other.End) && other.Start.Before(r.End)
+}
+
+// Span returns the spots held, once the hold has started.
+func (r *Reservation) Span() (SpotSpan, error) {
+	id, err := ParseSpotID(r.SpotID)
+	if err != nil {
+		return SpotSpan{}, err
+	}
+	return SpotSpan{Start: id, Len: max(r.SpotCount, 1)}, nil
+}
+
+// HeldSpots returns the spots a held reservation keeps, or an error if any is outside the
+// layout, of another type or held for another reservation.
+func (pl *ParkingLot) HeldSpots(r *Reservation) ([]*Spot, error) {
+	span, err := r.Span()
+	if err != nil {
+		return nil, err
+	}
+	spots, err := pl.SpotsOf(span)
+	if err != nil {
+		return nil, err
+	}
+	for _, spot := range spots {
+		switch {
+		case spot.SpotType != r.SpotType:
+			return nil, fmt.Errorf("spot type %q does not match spot %s of type %q", r.SpotType, spot.ID(), spot.SpotType)
+		case spot.HeldFor != "" && spot.HeldFor != r.ID:
+			return nil, fmt.Errorf("spot %s already held for %s", spot.ID(), spot.HeldFor)
+		}
+	}
+	return spots, nil
+}
+
+// RestoreReservation adds an open reservation to the lot, holding its spots if it is held.
+// seq is the last reservation number issued. AvailableSpots is left to the caller.
+func (pl *ParkingLot) RestoreReservation(r Reservation, seq uint64) error {
+	n, err := ReservationNumber(r.ID)
+	if err != nil {
+		return err
+	}
+	if n > seq {
+		return fmt.Errorf("made after reservation sequence %d", seq)
+	}
+	if _, exists := pl.Reservations[r.ID]; exists {
+		return fmt.Errorf("duplicate reservation")
+	}
+	if !r.Open() {
+		return fmt.Errorf("reservation is %s", r.Status)
+	}
+	if !r.Start.Before(r.End) {
+		return fmt.Errorf("window ends before it starts")
+	}
+	if r.Status == ReservationHeld {
+		if _, err := pl.HoldSpots(&r); err != nil {
+			return err
+		}
+	}
+	pl.Reservations[r.ID] = &r
+	return nil
+}
+
+// HoldSpots marks the spots of r, which must not be occupied, as held for it and returns
+// them.
+func (pl *ParkingLot) HoldSpots(r *Reservation) ([]*Spot, error) {
+	spots, err := pl.HeldSpots(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, spot := range spots {
+		if spot.Occupied {
+			return nil, fmt.Errorf("held spot %s occupied by %s", spot.ID(), spot.VehicleNumber)
+		}
+	}
+	for _, spot := range spots {
+		spot.HeldFor = r.ID
+	}
+	return spots, nil
+}
+
+// ReleaseSpots clears the hold of r and returns the spots it kept.
+func (pl *ParkingLot) ReleaseSpots(r *Reservation) []*Spot {
+	spots, err := pl.HeldSpots(r)
+	if err != nil {
+		return nil
+	}
+	for _, spot := range spots {
+		spot.HeldFor = ""
+	}
+	return spots
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the Snapshot format written by ParkingLot.Snapshot.
+const SnapshotVersion = 1
+
+// Snapshot is a consistent copy of a lot: its layout codes, every ticket issued and the
+// ticket sequence. Occupancy, VehicleMap, History and AvailableSpots are not stored; they
+// are derived from the open tickets when the snapshot is restored.
+type Snapshot struct {
+	Version   int          `json:"version"`
+	TakenAt   time.Time    `json:"taken_at"`
+	Floors    [][][]string `json:"floors"`
+	TicketSeq uint64       `json:"ticket_seq"`
+	EventSeq  uint64       `json:"event_seq"`
+	Tickets   []Ticket     `json:"tickets"`
+
+	ReservationSeq uint64        `json:"reservation_seq,omitempty"`
+	Reservations   []Reservation `json:"reservations,omitempty"` // open reservations only
+
+	// Parked lists the spots holding a vehicle when the snapshot was taken, read from the
+	// spots themselves. Lots run on a repository keep no tickets in memory, so this is
+	// the only record of their occupancy in a snapshot. It is not saved.
+	Parked []SpotID `json:"-"`
+}
+
+// SnapshotError aggregates every inconsistency found while restoring a snapshot.
+type SnapshotError struct {
+	Errors []error
+}
+
+func (e *SnapshotError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid snapshot: %d error(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *SnapshotError) Unwrap() []error {
+	return e.Errors
+}
+
+// Snapshot copies the lot under pl.Mutx. Tickets are ordered by ID, which is issue order.
+func (pl *ParkingLot) Snapshot(takenAt time.Time) *Snapshot {
+	pl.Mutx.RLock()
+	defer pl.Mutx.RUnlock()
+
+	snap := &Snapshot{
+		Version:   SnapshotVersion,
+		TakenAt:   takenAt,
+		Floors:    make([][][]string, len(pl.Layout)),
+		TicketSeq: pl.TicketSeq,
+		EventSeq:  pl.EventSeq,
+		Tickets:   make([]Ticket, 0, len(pl.Tickets)),
+	}
+	for f, floor := range pl.Layout {
+		snap.Floors[f] = make([][]string, len(floor))
+		for r, row := range floor {
+			snap.Floors[f][r] = make([]string, len(row))
+			for c, spot := range row {
+				snap.Floors[f][r][c] = spot.Code()
+				if spot.Occupied {
+					snap.Parked = append(snap.Parked, spot.SpotID())
+				}
+			}
+		}
+	}
+	for _, ticket := range pl.Tickets {
+		snap.Tickets = append(snap.Tickets, *ticket)
+	}
+	sort.Slice(snap.Tickets, func(i, j int) bool { return snap.Tickets[i].ID < snap.Tickets[j].ID })
+	snap.ReservationSeq = pl.ReservationSeq
+	for _, r := range pl.Reservations {
+		snap.Reservations = append(snap.Reservations, *r)
+	}
+	sort.Slice(snap.Reservations, func(i, j int) bool { return snap.Reservations[i].ID < snap.Reservations[j].ID })
+	return snap
+}
+
+// Occupied returns the spots held by the open tickets of the snapshot, or listed in
+// Parked.
+func (snap *Snapshot) Occupied() map[SpotID]bool {
+	occupied := make(map[SpotID]bool)
+	for _, id := range snap.Parked {
+		occupied[id] = true
+	}
+	for i := range snap.Tickets {
+		ticket := &snap.Tickets[i]
+		if !ticket.Active() {
+			continue
+		}
+		span, err := ticket.Span()
+		if err != nil {
+			continue
+		}
+		for c := range span.Len {
+			id := span.Start
+			id.Col += c
+			occupied[id] = true
+		}
+	}
+	return occupied
+}
+
+// RestoreTickets loads the snapshot's tickets into pl, a lot freshly built from
+// snap.Floors, in issue order and parks the vehicles of open tickets, then restores the
+// open reservations and their held spots. Every inconsistency is reported in a
+// *SnapshotError; AvailableSpots is left for the caller to rebuild.
+func (snap *Snapshot) RestoreTickets(pl *ParkingLot) error {
+	var errs []error
+	if snap.Version != SnapshotVersion {
+		errs = append(errs, fmt.Errorf("unsupported version %d, want %d", snap.Version, SnapshotVersion))
+	}
+
+	tickets := append([]Ticket(nil), snap.Tickets...)
+	sort.SliceStable(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
+	for i := range tickets {
+		ticket := &tickets[i]
+		if err := pl.restoreTicket(ticket, snap.TicketSeq); err != nil {
+			errs = append(errs, fmt.Errorf("ticket %q: %w", ticket.ID, err))
+		}
+	}
+	for _, r := range snap.Reservations {
+		if err := pl.RestoreReservation(r, snap.ReservationSeq); err != nil {
+			errs = append(errs, fmt.Errorf("reservation %q: %w", r.ID, err))
+		}
+	}
+	pl.TicketSeq = snap.TicketSeq
+	pl.EventSeq = snap.EventSeq
+	pl.ReservationSeq = snap.ReservationSeq
+
+	if len(errs) > 0 {
+		return &SnapshotError{Errors: errs}
+	}
+	return nil
+}
+
+func (pl *ParkingLot) restoreTicket(ticket *Ticket, seq uint64) error {
+	n, err := TicketNumber(ticket.ID)
+	if err != nil {
+		return err
+	}
+	if n > seq {
+		return fmt.Errorf("issued after ticket sequence %d", seq)
+	}
+	if _, exists := pl.Tickets[ticket.ID]; exists {
+		return fmt.Errorf("duplicate ticket")
+	}
+	if !ticket.Active() && ticket.ExitTime.Before(ticket.EntryTime) {
+		return fmt.Errorf("exit time before entry time")
+	}
+
+	if ticket.SpotCount < 0 {
+		return fmt.Errorf("negative spot count %d", ticket.SpotCount)
+	}
+	spots, err := pl.TicketSpots(ticket)
+	if err != nil {
+		return err
+	}
+
+	if ticket.Active() {
+		for _, spot := range spots {
+			if spot.Occupied {
+				return fmt.Errorf("spot %s already occupied by %s", spot.ID(), spot.VehicleNumber)
+			}
+			if spot.HeldFor != "" && spot.HeldFor != ticket.ReservationID {
+				return fmt.Errorf("spot %s held for %s", spot.ID(), spot.HeldFor)
+			}
+		}
+		if _, parked := pl.VehicleMap[ticket.VehicleNumber]; parked {
+			return fmt.Errorf("vehicle %s has more than one open ticket", ticket.VehicleNumber)
+		}
+		for _, spot := range spots {
+			spot.Occupied = true
+			spot.VehicleNumber = ticket.VehicleNumber
+		}
+		pl.VehicleMap[ticket.VehicleNumber] = ticket.SpotID
+	}
+
+	pl.Tickets[ticket.ID] = ticket
+	pl.History[ticket.VehicleNumber] = append(pl.History[ticket.VehicleNumber], ticket)
+	return nil
+}
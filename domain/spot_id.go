@@ -0,0 +1,306 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SpotID locates a spot by zero-based floor, row and column. Its canonical form, used in
+// tickets, events and snapshots, is "floor-row-col", e.g. "0-2-14".
+type SpotID struct {
+	Floor int
+	Row   int
+	Col   int
+}
+
+// ParseSpotID parses the canonical form. Each part must be a non-negative decimal number
+// without sign or leading zeros, so every spot has exactly one ID.
+func ParseSpotID(s string) (SpotID, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 {
+		return SpotID{}, fmt.Errorf("malformed spot ID %q, want FLOOR-ROW-COL", s)
+	}
+	var n [3]int
+	for i, part := range parts {
+		v, ok := parseIndex(part)
+		if !ok {
+			return SpotID{}, fmt.Errorf("malformed spot ID %q, want FLOOR-ROW-COL", s)
+		}
+		n[i] = v
+	}
+	return SpotID{Floor: n[0], Row: n[1], Col: n[2]}, nil
+}
+
+func parseIndex(s string) (int, bool) {
+	if s == "" || len(s) > 1 && s[0] == '0' || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+// Valid reports whether no part is negative.
+func (id SpotID) Valid() bool {
+	return id.Floor >= 0 && id.Row >= 0 && id.Col >= 0
+}
+
+// String returns the canonical form. IDs with negative parts cannot be told apart in that
+// form, so they are written as "invalid(f,r,c)", which ParseSpotID rejects.
+func (id SpotID) String() string {
+	if !id.Valid() {
+		return fmt.Sprintf("invalid(%d,%d,%d)", id.Floor, id.Row, id.Col)
+	}
+	return fmt.Sprintf("%d-%d-%d", id.Floor, id.Row, id.Col)
+}
+
+// SpotID returns the position of the spot.
+func (s *Spot) SpotID() SpotID {
+	return SpotID{Floor: s.Floor, Row: s.Row, Col: s.Col}
+}
+
+// Spot returns the spot at id, or an error if id is outside the layout.
+func (pl *ParkingLot) Spot(id SpotID) (*Spot, error) {
+	if !id.Valid() || id.Floor >= len(pl.Layout) || id.Row >= len(pl.Layout[id.Floor]) || id.Col >= len(pl.Layout[id.Floor][id.Row]) {
+		return nil, fmt.Errorf("spot %s not in layout", id)
+	}
+	return pl.Layout[id.Floor][id.Row][id.Col], nil
+}
+
+// SpotLabels writes spot IDs the way signs in the lot show them. With FloorPrefix "L" and
+// Base 1, floor 1, row 1, column 13 is "L2-B-14". The zero value gives "1-B-13".
+type SpotLabels struct {
+	FloorPrefix string   `json:"floor_prefix,omitempty" yaml:"floor_prefix,omitempty"` // written before the floor number
+	Floors      []string `json:"floors,omitempty" yaml:"floors,omitempty"`             // names of the first floors, e.g. "P1", "G"; later floors are numbered
+	Rows        []string `json:"rows,omitempty" yaml:"rows,omitempty"`                 // names of the first rows; later rows are lettered A..Z, AA, AB, ...
+	Base        int      `json:"base,omitempty" yaml:"base,omitempty"`                 // number shown for floor and column zero
+}
+
+// Validate rejects labels that are empty, contain "-", or could name two different spots.
+func (l SpotLabels) Validate() error {
+	if l.Base < 0 {
+		return fmt.Errorf("negative label base %d", l.Base)
+	}
+	if strings.Contains(l.FloorPrefix, "-") {
+		return fmt.Errorf("floor prefix %q contains \"-\"", l.FloorPrefix)
+	}
+	for _, list := range []struct {
+		kind  string
+		names []string
+	}{{"floor", l.Floors}, {"row", l.Rows}} {
+		kind, names := list.kind, list.names
+		for i, name := range names {
+			if name == "" || strings.Contains(name, "-") {
+				return fmt.Errorf("%s label %q must be non-empty without \"-\"", kind, name)
+			}
+			for _, other := range names[:i] {
+				if strings.EqualFold(name, other) {
+					return fmt.Errorf("duplicate %s label %q", kind, name)
+				}
+			}
+		}
+	}
+	for f, name := range l.Floors {
+		if n, ok := l.floorNumber(name); ok && n != f {
+			return fmt.Errorf("floor label %q also names floor %d", name, n)
+		}
+	}
+	for r, name := range l.Rows {
+		if n, ok := parseLetters(name); ok && n != r {
+			return fmt.Errorf("row label %q also names row %d", name, n)
+		}
+	}
+	return nil
+}
+
+// Format returns the label of id.
+func (l SpotLabels) Format(id SpotID) string {
+	if !id.Valid() {
+		return id.String()
+	}
+	floor := l.FloorPrefix + strconv.Itoa(id.Floor+l.Base)
+	if id.Floor < len(l.Floors) {
+		floor = l.Floors[id.Floor]
+	}
+	row := letters(id.Row)
+	if id.Row < len(l.Rows) {
+		row = l.Rows[id.Row]
+	}
+	return floor + "-" + row + "-" + strconv.Itoa(id.Col+l.Base)
+}
+
+// FormatSpan labels a span like SpotSpan.String does, e.g. "L2-B-4..6".
+func (l SpotLabels) FormatSpan(s SpotSpan) string {
+	if s.Len <= 1 || !s.Start.Valid() {
+		return l.Format(s.Start)
+	}
+	return fmt.Sprintf("%s..%d", l.Format(s.Start), s.Start.Col+s.Len-1+l.Base)
+}
+
+// Parse accepts a label as written by Format, ignoring case, or a canonical spot ID.
+func (l SpotLabels) Parse(s string) (SpotID, error) {
+	if parts := strings.Split(s, "-"); len(parts) == 3 {
+		floor, okFloor := l.floorIndex(parts[0])
+		row, okRow := l.rowIndex(parts[1])
+		col, okCol := parseIndex(parts[2])
+		if okFloor && okRow && okCol && col >= l.Base {
+			return SpotID{Floor: floor, Row: row, Col: col - l.Base}, nil
+		}
+	}
+	if id, err := ParseSpotID(s); err == nil {
+		return id, nil
+	}
+	return SpotID{}, fmt.Errorf("malformed spot ID %q, want a label like %q or FLOOR-ROW-COL", s, l.Format(SpotID{}))
+}
+
+func (l SpotLabels) floorIndex(s string) (int, bool) {
+	for f, name := range l.Floors {
+		if strings.EqualFold(s, name) {
+			return f, true
+		}
+	}
+	return l.floorNumber(s)
+}
+
+func (l SpotLabels) floorNumber(s string) (int, bool) {
+	if len(s) < len(l.FloorPrefix) || !strings.EqualFold(s[:len(l.FloorPrefix)], l.FloorPrefix) {
+		return 0, false
+	}
+	n, ok := parseIndex(s[len(l.FloorPrefix):])
+	if !ok || n < l.Base {
+		return 0, false
+	}
+	return n - l.Base, true
+}
+
+func (l SpotLabels) rowIndex(s string) (int, bool) {
+	for r, name := range l.Rows {
+		if strings.EqualFold(s, name) {
+			return r, true
+		}
+	}
+	return parseLetters(s)
+}
+
+// letters names row n like spreadsheet columns: A..Z, AA..AZ, BA, ... Only up to three
+// letters are parsed back, so longer row names never collide with a lettered row.
+func letters(n int) string {
+	var b []byte
+	for n++; n > 0; n = (n - 1) / 26 {
+		b = append([]byte{byte('A' + (n-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+func parseLetters(s string) (int, bool) {
+	if s == "" || len(s) > 3 {
+		return 0, false
+	}
+	n := 0
+	for _, c := range strings.ToUpper(s) {
+		if c < 'A' || c > 'Z' {
+			return 0, false
+		}
+		n = n*26 + int(c-'A') + 1
+	}
+	return n - 1, true
+}
+
+// SpotRange selects a whole floor, one row of a floor, or a single spot. Row and Col are
+// -1 when every row or column is selected.
+type SpotRange struct {
+	Floor int
+	Row   int
+	Col   int
+}
+
+// ParseSpotRange parses "FLOOR", "FLOOR-ROW" or a canonical spot ID.
+func ParseSpotRange(s string) (SpotRange, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) > 3 {
+		return SpotRange{}, fmt.Errorf("malformed spot range %q, want FLOOR, FLOOR-ROW or FLOOR-ROW-COL", s)
+	}
+	n := [3]int{-1, -1, -1}
+	for i, part := range parts {
+		v, ok := parseIndex(part)
+		if !ok {
+			return SpotRange{}, fmt.Errorf("malformed spot range %q, want FLOOR, FLOOR-ROW or FLOOR-ROW-COL", s)
+		}
+		n[i] = v
+	}
+	return SpotRange{Floor: n[0], Row: n[1], Col: n[2]}, nil
+}
+
+func (r SpotRange) String() string {
+	switch {
+	case r.Row < 0:
+		return strconv.Itoa(r.Floor)
+	case r.Col < 0:
+		return fmt.Sprintf("%d-%d", r.Floor, r.Row)
+	default:
+		return SpotID{Floor: r.Floor, Row: r.Row, Col: r.Col}.String()
+	}
+}
+
+// SpotsIn returns the spots in r, including pillars and empty cells, or an error if the
+// floor, row or spot is outside the layout.
+func (pl *ParkingLot) SpotsIn(r SpotRange) ([]*Spot, error) {
+	if r.Floor < 0 || r.Floor >= len(pl.Layout) {
+		return nil, fmt.Errorf("floor %d not in layout", r.Floor)
+	}
+	if r.Row < 0 {
+		var spots []*Spot
+		for _, row := range pl.Layout[r.Floor] {
+			spots = append(spots, row...)
+		}
+		return spots, nil
+	}
+	if r.Row >= len(pl.Layout[r.Floor]) {
+		return nil, fmt.Errorf("row %s not in layout", r)
+	}
+	if r.Col < 0 {
+		return append([]*Spot(nil), pl.Layout[r.Floor][r.Row]...), nil
+	}
+	spot, err := pl.Spot(SpotID{Floor: r.Floor, Row: r.Row, Col: r.Col})
+	if err != nil {
+		return nil, err
+	}
+	return []*Spot{spot}, nil
+}
+
+// SpotSpan is a run of Len adjacent spots in one row, starting at Start and going up in
+// column. Vehicles that take several spots hold a span.
+type SpotSpan struct {
+	Start SpotID
+	Len   int
+}
+
+// String writes a single spot as its ID and longer spans as "floor-row-first..last",
+// e.g. "0-1-3..5".
+func (s SpotSpan) String() string {
+	if s.Len <= 1 {
+		return s.Start.String()
+	}
+	return fmt.Sprintf("%s..%d", s.Start, s.Start.Col+s.Len-1)
+}
+
+// Contains reports whether id is one of the spots in s.
+func (s SpotSpan) Contains(id SpotID) bool {
+	return id.Floor == s.Start.Floor && id.Row == s.Start.Row && id.Col >= s.Start.Col && id.Col < s.Start.Col+max(s.Len, 1)
+}
+
+// SpotsOf returns the spots of span in column order, or an error if any is outside the
+// layout.
+func (pl *ParkingLot) SpotsOf(span SpotSpan) ([]*Spot, error) {
+	spots := make([]*Spot, max(span.Len, 1))
+	for i := range spots {
+		id := span.Start
+		id.Col += i
+		spot, err := pl.Spot(id)
+		if err != nil {
+			return nil, err
+		}
+		spots[i] = spot
+	}
+	return spots, nil
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestParseSpotID(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    SpotID
+		wantErr bool
+	}{
+		{"0-0-0", SpotID{0, 0, 0}, false},
+		{"2-13-140", SpotID{2, 13, 140}, false},
+		{"0-0", SpotID{}, true},
+		{"0-0-0-0", SpotID{}, true},
+		{"-1--2--3", SpotID{}, true},
+		{"+1-0-0", SpotID{}, true},
+		{"01-0-0", SpotID{}, true},
+		{"0-0-x", SpotID{}, true},
+		{"0-0-", SpotID{}, true},
+		{"0 -0-0", SpotID{}, true},
+		{"0-0-99999999999999999999", SpotID{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSpotID(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSpotID(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSpotID(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+			if err == nil && got.String() != tt.in {
+				t.Errorf("String() = %q, want %q", got.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestParkingLot_Spot(t *testing.T) {
+	layout, err := ParseLayout([][][]string{{{"B-1", "A-1"}}, {{"M-1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := &ParkingLot{Layout: layout}
+
+	for _, id := range []SpotID{{0, 0, 1}, {1, 0, 0}} {
+		spot, err := pl.Spot(id)
+		if err != nil || spot.SpotID() != id {
+			t.Errorf("Spot(%s) = %v, %v", id, spot, err)
+		}
+	}
+	for _, id := range []SpotID{{1, 0, 1}, {0, 1, 0}, {2, 0, 0}, {-1, 0, 0}} {
+		if _, err := pl.Spot(id); err == nil {
+			t.Errorf("Spot(%s) should fail", id)
+		}
+	}
+	if _, err := pl.SpotAt("0-0"); err == nil {
+		t.Error("SpotAt should reject a malformed ID")
+	}
+}
+
+func TestSpotLabels(t *testing.T) {
+	signs := SpotLabels{FloorPrefix: "L", Base: 1}
+	named := SpotLabels{Floors: []string{"P1", "G"}, Rows: []string{"North", "South"}}
+
+	tests := []struct {
+		name   string
+		labels SpotLabels
+		id     SpotID
+		label  string
+	}{
+		{"ZeroValue", SpotLabels{}, SpotID{1, 1, 13}, "1-B-13"},
+		{"Signs", signs, SpotID{1, 1, 13}, "L2-B-14"},
+		{"DoubleLetterRow", signs, SpotID{0, 27, 0}, "L1-AB-1"},
+		{"NamedFloor", named, SpotID{1, 0, 4}, "G-North-4"},
+		{"NumberedAfterNames", named, SpotID{2, 2, 0}, "2-C-0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.labels.Format(tt.id); got != tt.label {
+				t.Errorf("Format(%s) = %q, want %q", tt.id, got, tt.label)
+			}
+			got, err := tt.labels.Parse(tt.label)
+			if err != nil || got != tt.id {
+				t.Errorf("Parse(%q) = %s, %v, want %s", tt.label, got, err, tt.id)
+			}
+		})
+	}
+
+	if got := signs.FormatSpan(SpotSpan{Start: SpotID{1, 1, 3}, Len: 3}); got != "L2-B-4..6" {
+		t.Errorf("FormatSpan = %q, want L2-B-4..6", got)
+	}
+
+	for _, in := range []string{"l2-b-14", "1-1-13"} {
+		if got, err := signs.Parse(in); err != nil || got != (SpotID{1, 1, 13}) {
+			t.Errorf("Parse(%q) = %s, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"L0-A-1", "L1-A-0", "L1-1A-1", "X1-A-1", "L1-A"} {
+		if _, err := signs.Parse(in); err == nil {
+			t.Errorf("Parse(%q) should fail", in)
+		}
+	}
+}
+
+func TestSpotLabels_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  SpotLabels
+		wantErr bool
+	}{
+		{"ZeroValue", SpotLabels{}, false},
+		{"Named", SpotLabels{Floors: []string{"P1", "G"}, Rows: []string{"North"}}, false},
+		{"MatchingLetter", SpotLabels{Rows: []string{"A", "B"}}, false},
+		{"NegativeBase", SpotLabels{Base: -1}, true},
+		{"DashInPrefix", SpotLabels{FloorPrefix: "L-"}, true},
+		{"EmptyRow", SpotLabels{Rows: []string{""}}, true},
+		{"DuplicateFloor", SpotLabels{Floors: []string{"G", "g"}}, true},
+		{"FloorShadowsNumber", SpotLabels{FloorPrefix: "L", Floors: []string{"L3"}}, true},
+		{"RowShadowsLetter", SpotLabels{Rows: []string{"North", "A"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.labels.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpotSpan(t *testing.T) {
+	layout, err := ParseLayout([][][]string{{{"A-1", "A-1", "A-1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := &ParkingLot{Layout: layout}
+
+	tests := []struct {
+		span    SpotSpan
+		str     string
+		wantErr bool
+	}{
+		{SpotSpan{Start: SpotID{0, 0, 1}}, "0-0-1", false},
+		{SpotSpan{Start: SpotID{0, 0, 1}, Len: 1}, "0-0-1", false},
+		{SpotSpan{Start: SpotID{0, 0, 0}, Len: 3}, "0-0-0..2", false},
+		{SpotSpan{Start: SpotID{0, 0, 1}, Len: 3}, "0-0-1..3", true},
+	}
+	for _, tt := range tests {
+		if got := tt.span.String(); got != tt.str {
+			t.Errorf("String() = %q, want %q", got, tt.str)
+		}
+		spots, err := pl.SpotsOf(tt.span)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SpotsOf(%s): err = %v, wantErr %v", tt.span, err, tt.wantErr)
+		}
+		for i, spot := range spots {
+			if spot.Col != tt.span.Start.Col+i || !tt.span.Contains(spot.SpotID()) {
+				t.Errorf("SpotsOf(%s)[%d] = %s", tt.span, i, spot.ID())
+			}
+		}
+	}
+
+	span := SpotSpan{Start: SpotID{0, 0, 1}, Len: 2}
+	for id, want := range map[SpotID]bool{{0, 0, 0}: false, {0, 0, 1}: true, {0, 0, 2}: true, {0, 0, 3}: false, {0, 1, 1}: false} {
+		if got := span.Contains(id); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", id, got, want)
+		}
+	}
+}
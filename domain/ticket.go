@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"submit_do_it/constants"
+	"time"
+)
+
+// Ticket records one stay of a vehicle in a spot. ExitTime is zero while the vehicle is parked.
+type Ticket struct {
+	ID            string                `json:"id"`
+	VehicleNumber string                `json:"vehicle_number"`
+	VehicleType   constants.VehicleType `json:"vehicle_type"`
+	SpotID        string                `json:"spot_id"`
+	SpotType      constants.VehicleType `json:"spot_type,omitempty"`  // differs from VehicleType when the vehicle fell back to another spot type
+	SpotCount     int                   `json:"spot_count,omitempty"` // adjacent spots held from SpotID along the row; 0 means 1
+	EntryTime     time.Time             `json:"entry_time"`
+	ExitTime      time.Time             `json:"exit_time,omitzero"`
+
+	Fee       Fee    `json:"fee,omitzero"`
+	PaymentID string `json:"payment_id,omitempty"` // captured payment for Fee, empty when nothing was charged
+	Refunded  bool   `json:"refunded,omitempty"`
+
+	ReservationID string `json:"reservation_id,omitempty"` // reservation whose held spot the vehicle parked in
+}
+
+// Session is the stay record the lot kept before it issued tickets. A ticket carries the
+// same vehicle, spot, entry and exit time, and Active, so it replaces Session outright.
+//
+// Deprecated: use Ticket.
+type Session = Ticket
+
+// TicketID formats the ID of the n-th ticket issued by a lot, e.g. "T00000042".
+func TicketID(n uint64) string {
+	return fmt.Sprintf("T%08d", n)
+}
+
+// TicketNumber returns the sequence number in a ticket ID.
+func TicketNumber(id string) (uint64, error) {
+	var n uint64
+	if _, err := fmt.Sscanf(id, "T%d", &n); err != nil {
+		return 0, fmt.Errorf("malformed ticket ID %q", id)
+	}
+	return n, nil
+}
+
+// ParkedSpotType returns the type of the ticket's spot. Tickets issued before fallbacks
+// existed leave SpotType empty; their spot always matched the vehicle.
+func (t *Ticket) ParkedSpotType() constants.VehicleType {
+	if t.SpotType == "" {
+		return t.VehicleType
+	}
+	return t.SpotType
+}
+
+// Span returns the spots the ticket holds.
+func (t *Ticket) Span() (SpotSpan, error) {
+	id, err := ParseSpotID(t.SpotID)
+	if err != nil {
+		return SpotSpan{}, err
+	}
+	return SpotSpan{Start: id, Len: max(t.SpotCount, 1)}, nil
+}
+
+// SpotRange returns SpotID, or the whole run of spots for a vehicle that takes several,
+// e.g. "0-1-3..5".
+func (t *Ticket) SpotRange() string {
+	span, err := t.Span()
+	if err != nil {
+		return t.SpotID
+	}
+	return span.String()
+}
+
+func (t *Ticket) Active() bool {
+	return t.ExitTime.IsZero()
+}
+
+// Duration returns how long the vehicle stayed, or zero while it is still parked.
+func (t *Ticket) Duration() time.Duration {
+	if t.Active() {
+		return 0
+	}
+	return t.ExitTime.Sub(t.EntryTime)
+}
+
+// VehicleLocation is where a vehicle is parked, or where it was last parked when Parked is false.
+type VehicleLocation struct {
+	SpotID    string
+	SpotRange string // SpotID, or the run of spots held by a vehicle that takes several
+	Parked    bool
+	Ticket    Ticket
+}
+
+// Fee is an amount in minor currency units, e.g. cents.
+type Fee struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// Receipt is the result of unparking: the closed ticket and what was charged for it.
+type Receipt struct {
+	Ticket Ticket
+	Fee    Fee
+}
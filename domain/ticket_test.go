@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicketDuration(t *testing.T) {
+	entry := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		ticket   Ticket
+		active   bool
+		expected time.Duration
+	}{
+		{"Open", Ticket{EntryTime: entry}, true, 0},
+		{"Closed", Ticket{EntryTime: entry, ExitTime: entry.Add(90 * time.Minute)}, false, 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ticket.Active(); got != tt.active {
+				t.Errorf("Ticket.Active() = %v, want %v", got, tt.active)
+			}
+			if got := tt.ticket.Duration(); got != tt.expected {
+				t.Errorf("Ticket.Duration() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSession(t *testing.T) {
+	entry := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	session := Session{VehicleNumber: "CAR1", SpotID: "0-0-1", EntryTime: entry}
+	if !session.Active() {
+		t.Errorf("open session not active")
+	}
+	session.ExitTime = entry.Add(time.Hour)
+	if session.Active() {
+		t.Errorf("closed session still active")
+	}
+}
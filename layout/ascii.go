@@ -0,0 +1,186 @@
+package layout
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"unicode"
+)
+
+// ASCII floor plans draw each floor as a grid of single-character cells separated by
+// spaces. Floors are separated by blank lines and may start with a "[name]" header.
+//
+//	[Ground]
+//	B M A
+//	B . A
+//	# A A
+//
+// B, M and A are active spots of that type, "." is an inactive cell and "#" a pillar.
+// Types registered in constants.Default are drawn with their Symbol. Inactive spots of a
+// known type ("B-0") have no ASCII form and are drawn as ".".
+// When a live lot is rendered, occupied spots are drawn in lower case.
+var asciiCodes = map[rune]string{
+	'.': domain.EmptyCode,
+	'#': domain.PillarCode,
+}
+
+// asciiCode returns the layout code of a plan symbol.
+func asciiCode(symbol rune) (string, bool) {
+	if code, ok := asciiCodes[symbol]; ok {
+		return code, true
+	}
+	if spec, ok := constants.Default.LookupSymbol(string(symbol)); ok {
+		return string(spec.Type) + "-1", true
+	}
+	return "", false
+}
+
+func decodeASCII(data []byte) (*Lot, error) {
+	lot := &Lot{}
+	var errs []error
+	var floor *Floor
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			floor = nil
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			lot.Floors = append(lot.Floors, Floor{Name: line[1 : len(line)-1], Grid: [][]string{}})
+			floor = &lot.Floors[len(lot.Floors)-1]
+			continue
+		}
+		if floor == nil {
+			lot.Floors = append(lot.Floors, Floor{Grid: [][]string{}})
+			floor = &lot.Floors[len(lot.Floors)-1]
+		}
+
+		f, r := len(lot.Floors)-1, len(floor.Grid)
+		var row []string
+		for _, cell := range strings.Join(strings.Fields(line), "") {
+			code, ok := asciiCode(cell)
+			if !ok {
+				reason := fmt.Sprintf("unknown plan symbol %q", cell)
+				if _, upper := asciiCode(unicode.ToUpper(cell)); upper {
+					reason = fmt.Sprintf("occupied marker %q is not allowed in a plan", cell)
+				}
+				errs = append(errs, &domain.CellError{Floor: f, Row: r, Col: len(row), Code: string(cell), Reason: reason})
+			}
+			row = append(row, code)
+		}
+		floor.Grid = append(floor.Grid, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(errs) > 0 {
+		return nil, &domain.LayoutError{Errors: errs}
+	}
+	return lot, nil
+}
+
+func encodeASCII(lot *Lot) ([]byte, error) {
+	var buf bytes.Buffer
+	for f, floor := range lot.Floors {
+		if f > 0 {
+			buf.WriteString("\n")
+		}
+		if floor.Name != "" {
+			fmt.Fprintf(&buf, "[%s]\n", floor.Name)
+		}
+		for r, row := range floor.Grid {
+			cells := make([]string, len(row))
+			for c, code := range row {
+				cell, ok := asciiCell(code)
+				if !ok {
+					return nil, &domain.CellError{Floor: f, Row: r, Col: c, Code: code, Reason: "spot type has no ASCII symbol"}
+				}
+				cells[c] = cell
+			}
+			buf.WriteString(strings.Join(cells, " ") + "\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// asciiCell returns the plan symbol of a layout code. ok is false for active spots of a
+// type without a symbol, which are drawn as "?".
+func asciiCell(code string) (cell string, ok bool) {
+	switch code {
+	case domain.PillarCode:
+		return "#", true
+	case domain.EmptyCode:
+		return ".", true
+	}
+
+	vt, active, err := domain.ParseSpotCode(code)
+	if err != nil || !active {
+		return ".", true
+	}
+	if spec, _ := constants.Lookup(vt); spec.Symbol != "" {
+		return spec.Symbol, true
+	}
+	return "?", false
+}
+
+// ParseASCII parses an ASCII floor plan into spot grids, one per floor.
+func ParseASCII(data []byte) ([][][]*domain.Spot, error) {
+	lot, err := decodeASCII(data)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ParseLayout(lot.Templates())
+}
+
+// RenderASCII draws every floor of a lot snapshot, marking occupied spots in lower case.
+func RenderASCII(w io.Writer, snap *domain.Snapshot) error {
+	occupied := snap.Occupied()
+	for f := range snap.Floors {
+		if f > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := renderFloor(w, snap, occupied, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderFloorASCII draws a single floor of a lot snapshot, marking occupied spots in lower
+// case.
+func RenderFloorASCII(w io.Writer, snap *domain.Snapshot, floor int) error {
+	if floor < 0 || floor >= len(snap.Floors) {
+		return fmt.Errorf("floor %d out of range", floor)
+	}
+	return renderFloor(w, snap, snap.Occupied(), floor)
+}
+
+func renderFloor(w io.Writer, snap *domain.Snapshot, occupied map[domain.SpotID]bool, floor int) error {
+	if _, err := fmt.Fprintf(w, "[Floor %d]\n", floor); err != nil {
+		return err
+	}
+	for r, row := range snap.Floors[floor] {
+		cells := make([]string, len(row))
+		for c, code := range row {
+			cell, _ := asciiCell(code)
+			if occupied[domain.SpotID{Floor: floor, Row: r, Col: c}] {
+				cell = strings.ToLower(cell)
+			}
+			cells[c] = cell
+		}
+		if _, err := io.WriteString(w, strings.Join(cells, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
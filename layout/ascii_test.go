@@ -0,0 +1,170 @@
+package layout
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/usecases"
+)
+
+const samplePlan = `[Ground]
+B B #
+B . B
+
+[Deck 1]
+A M A
+A A .
+`
+
+func TestDecode_ASCII(t *testing.T) {
+	lot, err := Decode([]byte(samplePlan), ASCII)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	want := &Lot{Floors: []Floor{
+		{Name: "Ground", Grid: [][]string{
+			{"B-1", "B-1", "#"},
+			{"B-1", ".", "B-1"},
+		}},
+		{Name: "Deck 1", Grid: [][]string{
+			{"A-1", "M-1", "A-1"},
+			{"A-1", "A-1", "."},
+		}},
+	}}
+	if !reflect.DeepEqual(lot, want) {
+		t.Errorf("Decode = %+v, want %+v", lot, want)
+	}
+
+	data, err := Encode(lot, ASCII)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if string(data) != samplePlan {
+		t.Errorf("Encode = %q, want %q", data, samplePlan)
+	}
+}
+
+func TestParseASCII(t *testing.T) {
+	layout, err := ParseASCII([]byte("BMA\nB.#\n\nAA\nAA\n"))
+	if err != nil {
+		t.Fatalf("ParseASCII failed: %v", err)
+	}
+	if len(layout) != 2 {
+		t.Fatalf("expected 2 floors, got %d", len(layout))
+	}
+
+	tests := []struct {
+		f, r, c int
+		vt      constants.VehicleType
+		active  bool
+		pillar  bool
+	}{
+		{0, 0, 0, constants.Bicycle, true, false},
+		{0, 0, 1, constants.Motorcycle, true, false},
+		{0, 0, 2, constants.Automobile, true, false},
+		{0, 1, 1, "", false, false},
+		{0, 1, 2, "", false, true},
+		{1, 1, 1, constants.Automobile, true, false},
+	}
+	for _, tt := range tests {
+		spot := layout[tt.f][tt.r][tt.c]
+		if spot.SpotType != tt.vt || spot.Active != tt.active || spot.Pillar != tt.pillar {
+			t.Errorf("spot at %d-%d-%d = %+v, want type %q active %v pillar %v", tt.f, tt.r, tt.c, spot, tt.vt, tt.active, tt.pillar)
+		}
+	}
+}
+
+func TestParseASCII_Errors(t *testing.T) {
+	_, err := ParseASCII([]byte("B X\nb A\n"))
+	var layoutErr *domain.LayoutError
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("expected *domain.LayoutError, got %v", err)
+	}
+	if len(layoutErr.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "floor 0 row 1 col 0: occupied marker") {
+		t.Errorf("expected occupied marker error, got %q", err.Error())
+	}
+
+	if _, err := ParseASCII([]byte("B B\nB\n")); err == nil {
+		t.Errorf("expected error for ragged rows, got nil")
+	}
+}
+
+func TestRenderASCII(t *testing.T) {
+	lot, err := Decode([]byte(samplePlan), ASCII)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	u, err := usecases.NewParkingLotUsecaseFromFloorsE(lot.Templates())
+	if err != nil {
+		t.Fatalf("NewParkingLotUsecaseFromFloorsE failed: %v", err)
+	}
+	if _, err := u.Park(constants.Motorcycle, "MOTO1"); err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderFloorASCII(&buf, u.Snapshot(), 1); err != nil {
+		t.Fatalf("RenderFloorASCII failed: %v", err)
+	}
+	if want := "[Floor 1]\nA m A\nA A .\n"; buf.String() != want {
+		t.Errorf("RenderFloorASCII = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := RenderASCII(&buf, u.Snapshot()); err != nil {
+		t.Fatalf("RenderASCII failed: %v", err)
+	}
+	if want := "[Floor 0]\nB B #\nB . B\n\n[Floor 1]\nA m A\nA A .\n"; buf.String() != want {
+		t.Errorf("RenderASCII = %q, want %q", buf.String(), want)
+	}
+
+	if err := RenderFloorASCII(&buf, u.Snapshot(), 5); err == nil {
+		t.Errorf("expected error for out of range floor, got nil")
+	}
+}
+
+func TestASCII_RegisteredTypes(t *testing.T) {
+	if _, known := constants.Lookup("EV"); !known {
+		err := constants.Register(
+			constants.TypeSpec{Type: "EV", Name: "EV charging", Symbol: "E"},
+			constants.TypeSpec{Type: "BUS", Name: "bus"},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lot, err := Decode([]byte("E A\n"), ASCII)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lot.Floors[0].Grid[0]; !reflect.DeepEqual(got, []string{"EV-1", "A-1"}) {
+		t.Errorf("grid = %v", got)
+	}
+
+	u, err := usecases.NewParkingLotUsecaseFromFloorsE(lot.Templates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Park("EV", "TESLA1")
+	var buf bytes.Buffer
+	if err := RenderASCII(&buf, u.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[Floor 0]\ne A\n"; buf.String() != want {
+		t.Errorf("render = %q, want %q", buf.String(), want)
+	}
+
+	if _, err := Encode(&Lot{Floors: []Floor{{Grid: [][]string{{"BUS-1"}}}}}, ASCII); err == nil {
+		t.Error("expected error encoding a type without a symbol")
+	}
+}
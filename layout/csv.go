@@ -0,0 +1,107 @@
+package layout
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CSV layouts are spreadsheet friendly: grid rows are written as-is, and rows whose first
+// cell is a keyword carry everything else.
+//
+//	name,Central Garage
+//	meta,city,Jakarta
+//	floor,Ground
+//	B-1,B-1,B-0
+//	floor,Deck 1
+//	A-1,A-1,A-1
+const (
+	csvName  = "name"
+	csvMeta  = "meta"
+	csvFloor = "floor"
+)
+
+func decodeCSV(data []byte) (*Lot, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	lot := &Lot{}
+	for i, record := range records {
+		line := i + 1
+		switch strings.ToLower(record[0]) {
+		case csvName:
+			if len(record) < 2 {
+				return nil, fmt.Errorf("line %d: name row needs a value", line)
+			}
+			lot.Name = record[1]
+		case csvMeta:
+			if len(record) < 3 {
+				return nil, fmt.Errorf("line %d: meta row needs a key and a value", line)
+			}
+			if lot.Metadata == nil {
+				lot.Metadata = make(map[string]string)
+			}
+			lot.Metadata[record[1]] = record[2]
+		case csvFloor:
+			floor := Floor{Grid: [][]string{}}
+			if len(record) > 1 {
+				floor.Name = record[1]
+			}
+			lot.Floors = append(lot.Floors, floor)
+		default:
+			if len(lot.Floors) == 0 {
+				return nil, fmt.Errorf("line %d: grid row before any floor row", line)
+			}
+			floor := &lot.Floors[len(lot.Floors)-1]
+			floor.Grid = append(floor.Grid, trimTrailingEmpty(record))
+		}
+	}
+	return lot, nil
+}
+
+func encodeCSV(lot *Lot) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if lot.Name != "" {
+		writer.Write([]string{csvName, lot.Name})
+	}
+
+	keys := make([]string, 0, len(lot.Metadata))
+	for key := range lot.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writer.Write([]string{csvMeta, key, lot.Metadata[key]})
+	}
+
+	for _, floor := range lot.Floors {
+		writer.Write([]string{csvFloor, floor.Name})
+		for _, row := range floor.Grid {
+			writer.Write(row)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Spreadsheets pad short rows with empty cells when saving.
+func trimTrailingEmpty(record []string) []string {
+	for len(record) > 0 && record[len(record)-1] == "" {
+		record = record[:len(record)-1]
+	}
+	return record
+}
@@ -0,0 +1,21 @@
+package layout
+
+import (
+	"encoding/json"
+)
+
+func decodeJSON(data []byte) (*Lot, error) {
+	var lot Lot
+	if err := json.Unmarshal(data, &lot); err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
+
+func encodeJSON(lot *Lot) ([]byte, error) {
+	data, err := json.MarshalIndent(lot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
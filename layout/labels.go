@@ -0,0 +1,42 @@
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"submit_do_it/domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadLabels reads the spot labels signs in the lot use, from a .json or .yaml file:
+//
+//	floor_prefix: L
+//	floors: [P1, G]
+//	rows: [North, South]
+//	base: 1
+func LoadLabels(path string) (domain.SpotLabels, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.SpotLabels{}, err
+	}
+
+	var labels domain.SpotLabels
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &labels)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &labels)
+	default:
+		return domain.SpotLabels{}, fmt.Errorf("unsupported labels file extension %q", filepath.Ext(path))
+	}
+	if err == nil {
+		err = labels.Validate()
+	}
+	if err != nil {
+		return domain.SpotLabels{}, fmt.Errorf("labels %s: %w", path, err)
+	}
+	return labels, nil
+}
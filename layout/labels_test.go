@@ -0,0 +1,46 @@
+package layout
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"submit_do_it/domain"
+)
+
+func TestLoadLabels(t *testing.T) {
+	dir := t.TempDir()
+	want := domain.SpotLabels{FloorPrefix: "L", Floors: []string{"P1", "G"}, Rows: []string{"North"}, Base: 1}
+
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		wantErr string
+	}{
+		{"YAML", "labels.yaml", "floor_prefix: L\nfloors: [P1, G]\nrows: [North]\nbase: 1\n", ""},
+		{"JSON", "labels.json", `{"floor_prefix": "L", "floors": ["P1", "G"], "rows": ["North"], "base": 1}`, ""},
+		{"Duplicate", "dup.yaml", "rows: [A, a]\n", "duplicate row label"},
+		{"Extension", "labels.txt", "", "unsupported labels file extension"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadLabels(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadLabels = %+v, %v, want %+v", got, err, want)
+			}
+		})
+	}
+}
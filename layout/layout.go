@@ -0,0 +1,149 @@
+package layout
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"submit_do_it/domain"
+)
+
+type Format string
+
+const (
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
+	ASCII Format = "ascii"
+)
+
+type Floor struct {
+	Name string     `json:"name,omitempty"`
+	Grid [][]string `json:"grid"`
+}
+
+// Lot is a parking lot definition as written by facilities staff: lot metadata plus
+// one grid of layout codes ("B-1", "M-0", "A-1", ...) per floor.
+type Lot struct {
+	Name     string            `json:"name,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Floors   []Floor           `json:"floors"`
+}
+
+// Templates returns the per-floor code grids accepted by usecases.NewParkingLotUsecaseFromFloors.
+func (l *Lot) Templates() [][][]string {
+	templates := make([][][]string, len(l.Floors))
+	for f, floor := range l.Floors {
+		templates[f] = floor.Grid
+	}
+	return templates
+}
+
+// Validate reports every invalid code in the lot, located by floor, row and column.
+func (l *Lot) Validate() error {
+	_, err := domain.ParseLayout(l.Templates())
+	return err
+}
+
+// FromSnapshot exports the layout of a lot snapshot. Name and Metadata are left for the
+// caller.
+func FromSnapshot(snap *domain.Snapshot) *Lot {
+	lot := &Lot{Floors: make([]Floor, len(snap.Floors))}
+	for f, floor := range snap.Floors {
+		grid := make([][]string, len(floor))
+		for r, row := range floor {
+			grid[r] = append([]string(nil), row...)
+		}
+		lot.Floors[f] = Floor{Grid: grid}
+	}
+	return lot
+}
+
+// FormatFromPath picks a format from the file extension.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return JSON, nil
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".csv":
+		return CSV, nil
+	case ".txt":
+		return ASCII, nil
+	default:
+		return "", fmt.Errorf("unsupported layout file extension %q", filepath.Ext(path))
+	}
+}
+
+// Decode reads a lot definition in the given format and validates its codes.
+func Decode(data []byte, format Format) (*Lot, error) {
+	var (
+		lot *Lot
+		err error
+	)
+	switch format {
+	case JSON:
+		lot, err = decodeJSON(data)
+	case YAML:
+		lot, err = decodeYAML(data)
+	case CSV:
+		lot, err = decodeCSV(data)
+	case ASCII:
+		lot, err = decodeASCII(data)
+	default:
+		return nil, fmt.Errorf("unsupported layout format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode %s layout: %w", format, err)
+	}
+
+	if err := lot.Validate(); err != nil {
+		return nil, err
+	}
+	return lot, nil
+}
+
+// Encode writes a lot definition in the given format. ASCII output cannot represent
+// inactive spots of a known type and draws them as empty cells.
+func Encode(lot *Lot, format Format) ([]byte, error) {
+	switch format {
+	case JSON:
+		return encodeJSON(lot)
+	case YAML:
+		return encodeYAML(lot)
+	case CSV:
+		return encodeCSV(lot)
+	case ASCII:
+		return encodeASCII(lot)
+	default:
+		return nil, fmt.Errorf("unsupported layout format %q", format)
+	}
+}
+
+// LoadFile reads a lot definition, choosing the format from the file extension.
+func LoadFile(path string) (*Lot, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data, format)
+}
+
+// SaveFile writes a lot definition, choosing the format from the file extension.
+func SaveFile(path string, lot *Lot) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := Encode(lot, format)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
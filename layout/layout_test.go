@@ -0,0 +1,152 @@
+package layout
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/usecases"
+)
+
+func sampleLot() *Lot {
+	return &Lot{
+		Name:     "Central Garage",
+		Metadata: map[string]string{"city": "Jakarta", "operator": "Acme"},
+		Floors: []Floor{
+			{Name: "Ground", Grid: [][]string{
+				{"B-1", "B-1", "B-0"},
+			}},
+			{Name: "Deck 1", Grid: [][]string{
+				{"A-1", "A-1"},
+				{"M-1", "A-0"},
+			}},
+		},
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+	}{
+		{"JSON", JSON, `{
+  "name": "Central Garage",
+  "metadata": {"city": "Jakarta", "operator": "Acme"},
+  "floors": [
+    {"name": "Ground", "grid": [["B-1", "B-1", "B-0"]]},
+    {"name": "Deck 1", "grid": [["A-1", "A-1"], ["M-1", "A-0"]]}
+  ]
+}`},
+		{"YAML", YAML, `name: Central Garage
+metadata:
+  city: Jakarta
+  operator: Acme
+floors:
+  - name: Ground
+    grid:
+      - [B-1, B-1, B-0]
+  - name: Deck 1
+    grid:
+      - [A-1, A-1]
+      - [M-1, A-0]
+`},
+		{"CSV", CSV, `name,Central Garage
+meta,city,Jakarta
+meta,operator,Acme
+floor,Ground
+B-1,B-1,B-0
+floor,Deck 1
+A-1,A-1,
+M-1,A-0,
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lot, err := Decode([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if !reflect.DeepEqual(lot, sampleLot()) {
+				t.Errorf("Decode = %+v, want %+v", lot, sampleLot())
+			}
+		})
+	}
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	for _, format := range []Format{JSON, YAML, CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := Encode(sampleLot(), format)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			lot, err := Decode(data, format)
+			if err != nil {
+				t.Fatalf("Decode failed: %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(lot, sampleLot()) {
+				t.Errorf("round trip = %+v, want %+v", lot, sampleLot())
+			}
+		})
+	}
+}
+
+func TestDecode_InvalidCodes(t *testing.T) {
+	_, err := Decode([]byte("floor,Ground\nB-1,X-1\n"), CSV)
+	var layoutErr *domain.LayoutError
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("expected *domain.LayoutError, got %v", err)
+	}
+
+	if _, err := Decode([]byte("B-1,B-1\n"), CSV); err == nil {
+		t.Errorf("expected error for grid row before floor row, got nil")
+	}
+	if _, err := Decode([]byte("{"), JSON); err == nil {
+		t.Errorf("expected error for malformed JSON, got nil")
+	}
+	if _, err := Decode(nil, Format("xml")); err == nil {
+		t.Errorf("expected error for unsupported format, got nil")
+	}
+}
+
+func TestLoadFile_SaveFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"lot.json", "lot.yaml", "lot.yml", "lot.csv"} {
+		path := filepath.Join(dir, name)
+		if err := SaveFile(path, sampleLot()); err != nil {
+			t.Fatalf("SaveFile(%s) failed: %v", name, err)
+		}
+		lot, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile(%s) failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(lot, sampleLot()) {
+			t.Errorf("LoadFile(%s) = %+v, want %+v", name, lot, sampleLot())
+		}
+	}
+
+	if _, err := LoadFile(filepath.Join(dir, "lot.txt")); err == nil {
+		t.Errorf("expected error for unknown extension, got nil")
+	}
+}
+
+func TestFromSnapshot(t *testing.T) {
+	lot := sampleLot()
+	u, err := usecases.NewParkingLotUsecaseFromFloorsE(lot.Templates())
+	if err != nil {
+		t.Fatalf("NewParkingLotUsecaseFromFloorsE failed: %v", err)
+	}
+	if _, err := u.Park(constants.Automobile, "CAR123"); err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+
+	exported := FromSnapshot(u.Snapshot())
+	if !reflect.DeepEqual(exported.Templates(), lot.Templates()) {
+		t.Errorf("exported templates = %v, want %v", exported.Templates(), lot.Templates())
+	}
+}
@@ -0,0 +1,64 @@
+package layout
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+type yamlLot struct {
+	Name     string            `yaml:"name,omitempty"`
+	Metadata map[string]string `yaml:"metadata,omitempty"`
+	Floors   []yamlFloor       `yaml:"floors"`
+}
+
+type yamlFloor struct {
+	Name string    `yaml:"name,omitempty"`
+	Grid []yamlRow `yaml:"grid"`
+}
+
+// yamlRow is written in flow style so each grid row stays on one line.
+type yamlRow []string
+
+func (r yamlRow) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, code := range r {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: code})
+	}
+	return node, nil
+}
+
+func decodeYAML(data []byte) (*Lot, error) {
+	var yl yamlLot
+	if err := yaml.Unmarshal(data, &yl); err != nil {
+		return nil, err
+	}
+
+	lot := &Lot{
+		Name:     yl.Name,
+		Metadata: yl.Metadata,
+		Floors:   make([]Floor, len(yl.Floors)),
+	}
+	for f, floor := range yl.Floors {
+		grid := make([][]string, len(floor.Grid))
+		for r, row := range floor.Grid {
+			grid[r] = row
+		}
+		lot.Floors[f] = Floor{Name: floor.Name, Grid: grid}
+	}
+	return lot, nil
+}
+
+func encodeYAML(lot *Lot) ([]byte, error) {
+	yl := yamlLot{
+		Name:     lot.Name,
+		Metadata: lot.Metadata,
+		Floors:   make([]yamlFloor, len(lot.Floors)),
+	}
+	for f, floor := range lot.Floors {
+		grid := make([]yamlRow, len(floor.Grid))
+		for r, row := range floor.Grid {
+			grid[r] = row
+		}
+		yl.Floors[f] = yamlFloor{Name: floor.Name, Grid: grid}
+	}
+	return yaml.Marshal(yl)
+}
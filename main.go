@@ -1,58 +1,117 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
+	"os"
 	"submit_do_it/constants"
+	"submit_do_it/delivery/cli"
+	"submit_do_it/layout"
+	"submit_do_it/pricing"
 	"submit_do_it/usecases"
 )
 
-type vehicleInfo struct {
-	vehicleType   constants.VehicleType
-	vehicleNumber string
+// Usage: parkinglot [-types FILE] [-layout FILE] [-pricing FILE] [-labels FILE] [-waitlist fifo|priority] [-batch [-output text|json] [-continue-on-error]] [SCRIPT]
+//
+// Without SCRIPT commands are read from stdin. When stdin is a terminal the shell is
+// interactive; otherwise, or when SCRIPT is given, it runs in script mode and exits with
+// the code of the first command that failed: 2 usage, 3 invalid input, 4 not found,
+// 5 already parked, reserved or waiting, 6 lot full, 7 ticket state or feature disabled,
+// 8 payment declined, 1 anything else.
+// -batch prints one result line per command instead.
+func main() {
+	os.Exit(run())
 }
 
-func main() {
-	layout := [][]string{
-		{"B-1", "M-1", "A-1"},
-		{"B-1", "X-0", "A-1"},
-		{"M-1", "A-1", "B-1"},
+func run() int {
+	typesPath := flag.String("types", "", "optional file declaring extra vehicle and spot types and fallbacks (.json or .yaml)")
+	layoutPath := flag.String("layout", "", "optional lot layout file to start with")
+	pricingPath := flag.String("pricing", "", "optional pricing file (.json or .yaml)")
+	labelsPath := flag.String("labels", "", "optional file naming floors and rows, so spots can be given as labels such as L2-B-14 (.json or .yaml)")
+	batch := flag.Bool("batch", false, "print one result line per command")
+	output := flag.String("output", "text", "batch output format: text or json")
+	continueOnError := flag.Bool("continue-on-error", false, "keep running a batch after a command fails")
+	grace := flag.Duration("reservation-grace", usecases.DefaultReservationGrace, "how long a reserved spot waits for a late vehicle")
+	waitlist := flag.String("waitlist", "", "let vehicles turned away from a full lot wait for a spot, served fifo or by priority")
+	claim := flag.Duration("waitlist-claim", usecases.DefaultWaitlistClaim, "how long a spot offered from the waitlist waits for its vehicle")
+	flag.Parse()
+
+	opts := []usecases.Option{usecases.WithReservationGrace(*grace)}
+	if *typesPath != "" {
+		config, err := constants.ReadTypes(*typesPath)
+		if err == nil {
+			err = config.Register(constants.Default)
+		}
+		if err != nil {
+			fatalf("load types: %v", err)
+		}
+		opts = append(opts, usecases.WithCompatibility(config.Compatibility))
 	}
-	vehicles := []vehicleInfo{
-		{vehicleType: constants.Bicycle, vehicleNumber: "BIKE123"},
-		{vehicleType: constants.Motorcycle, vehicleNumber: "MOTO123"},
-		{vehicleType: constants.Automobile, vehicleNumber: "CAR123"},
+	if *pricingPath != "" {
+		engine, err := pricing.Load(*pricingPath)
+		if err != nil {
+			fatalf("load pricing: %v", err)
+		}
+		opts = append(opts, usecases.WithPricer(engine))
 	}
-
-	for _, v := range vehicles {
-		runVehicle(layout, v.vehicleType, v.vehicleNumber)
+	if *labelsPath != "" {
+		labels, err := layout.LoadLabels(*labelsPath)
+		if err != nil {
+			fatalf("load labels: %v", err)
+		}
+		opts = append(opts, usecases.WithSpotLabels(labels))
+	}
+	if *waitlist != "" {
+		order, err := usecases.ParseWaitlistOrder(*waitlist)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		opts = append(opts, usecases.WithWaitlist(order, *claim))
 	}
-}
 
-func runVehicle(layout [][]string, vehicleType constants.VehicleType, vehicleNumber string) {
-	pl := usecases.NewParkingLotUsecase(2, 3, 3, layout)
+	var in io.Reader = os.Stdin
+	interactive := isTerminal(os.Stdin)
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fatalf("open script: %v", err)
+		}
+		defer f.Close()
+		in, interactive = f, false
+	}
 
-	fmt.Printf("Vehicle number: %#v\n", vehicleNumber)
+	if *batch {
+		b := cli.NewBatch(opts...)
+		b.Format = cli.OutputFormat(*output)
+		b.ContinueOnError = *continueOnError
+		loadLayout(b.Shell, *layoutPath)
+		return b.Run(in, os.Stdout)
+	}
 
-	// Park vehicle. Given a vehicle type, assign an empty parking spot id and map the vehicleNumber. spotId is floor-row-column. If no free spot is found, return an error.
-	spotId, err := pl.Park(vehicleType, vehicleNumber)
-	if err != nil {
-		fmt.Printf("Park failed: %#v\n", err)
+	shell := cli.NewShell(os.Stdout, opts...)
+	loadLayout(shell, *layoutPath)
+	if interactive {
+		fmt.Println("Parking lot shell, type help for commands.")
 	}
-	fmt.Printf("%s Parked at: %#v\n", vehicleType, spotId)
+	return shell.Run(in, interactive)
+}
 
-	// Unpark vehicle. Removes vehicle from parking spot. Return an error for failure to unpark a vehicle.
-	err = pl.Unpark(spotId, vehicleNumber)
-	if err != nil {
-		fmt.Printf("Unpark failed: %#v\n", err)
+func loadLayout(shell *cli.Shell, path string) {
+	if path == "" {
+		return
+	}
+	if err := shell.LoadLayout(path); err != nil {
+		fatalf("load layout: %v", err)
 	}
+}
 
-	// Available spot. Display the free spots for each vehicle type.
-	fmt.Printf("Available %s spots: %#v\n", vehicleType, pl.AvailableSpot(vehicleType))
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-	// Search vehicle. If the vehicle has been unparked, get its last spotId
-	spot, err := pl.SearchVehicle(vehicleNumber)
-	if err != nil {
-		fmt.Printf("SearchVehicle failed: %#v\n", err)
-	}
-	fmt.Printf("Vehicle location: %#v\n\n", spot)
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(2)
 }
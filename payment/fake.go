@@ -0,0 +1,132 @@
+package payment
+
+import (
+	"fmt"
+	"submit_do_it/domain"
+	"submit_do_it/usecases"
+	"sync"
+)
+
+type Status string
+
+const (
+	Authorized Status = "authorized"
+	Captured   Status = "captured"
+	Voided     Status = "voided"
+	Refunded   Status = "refunded"
+)
+
+type Payment struct {
+	ID        string
+	Reference string
+	Fee       domain.Fee
+	Status    Status
+}
+
+// FakeGateway is an in-memory usecases.PaymentGateway for tests and local runs. It approves
+// everything except amounts above Limit (when set), authorizations queued to fail with
+// DeclineNext and captures queued to fail with FailNextCaptures.
+type FakeGateway struct {
+	Limit int64
+
+	mu       sync.Mutex
+	payments map[string]*Payment
+	declines int
+	failures int
+	seq      int
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		payments: make(map[string]*Payment),
+	}
+}
+
+// DeclineNext makes the next n authorizations fail.
+func (g *FakeGateway) DeclineNext(n int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.declines += n
+}
+
+// FailNextCaptures makes the next n captures fail.
+func (g *FakeGateway) FailNextCaptures(n int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.failures += n
+}
+
+func (g *FakeGateway) Authorize(reference string, fee domain.Fee) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.declines > 0 {
+		g.declines--
+		return "", fmt.Errorf("%w: card declined", usecases.ErrPaymentDeclined)
+	}
+	if g.Limit > 0 && fee.Amount > g.Limit {
+		return "", fmt.Errorf("%w: amount %d exceeds limit %d", usecases.ErrPaymentDeclined, fee.Amount, g.Limit)
+	}
+
+	g.seq++
+	payment := &Payment{
+		ID:        fmt.Sprintf("PAY%06d", g.seq),
+		Reference: reference,
+		Fee:       fee,
+		Status:    Authorized,
+	}
+	g.payments[payment.ID] = payment
+	return payment.ID, nil
+}
+
+func (g *FakeGateway) Capture(authorizationID string) error {
+	g.mu.Lock()
+	failed := g.failures > 0
+	if failed {
+		g.failures--
+	}
+	g.mu.Unlock()
+	if failed {
+		return fmt.Errorf("capture of %s failed", authorizationID)
+	}
+	return g.transition(authorizationID, Authorized, Captured)
+}
+
+func (g *FakeGateway) Void(authorizationID string) error {
+	return g.transition(authorizationID, Authorized, Voided)
+}
+
+// Refund pays back a captured payment. Refunding it again is a no-op.
+func (g *FakeGateway) Refund(authorizationID string) error {
+	if payment, ok := g.Payments()[authorizationID]; ok && payment.Status == Refunded {
+		return nil
+	}
+	return g.transition(authorizationID, Captured, Refunded)
+}
+
+func (g *FakeGateway) transition(id string, from, to Status) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[id]
+	if !ok {
+		return fmt.Errorf("payment %s not found", id)
+	}
+	if payment.Status != from {
+		return fmt.Errorf("payment %s is %s, want %s", id, payment.Status, from)
+	}
+	payment.Status = to
+	return nil
+}
+
+// Payments returns a copy of every payment seen so far, keyed by ID.
+func (g *FakeGateway) Payments() map[string]Payment {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payments := make(map[string]Payment, len(g.payments))
+	for id, payment := range g.payments {
+		payments[id] = *payment
+	}
+	return payments
+}
@@ -0,0 +1,201 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/pricing"
+	"submit_do_it/usecases"
+)
+
+func TestFakeGateway(t *testing.T) {
+	g := NewFakeGateway()
+	fee := domain.Fee{Amount: 5000, Currency: "IDR"}
+
+	id, err := g.Authorize("T1", fee)
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	if err := g.Refund(id); err == nil {
+		t.Errorf("expected refund of uncaptured payment to fail, got nil")
+	}
+	if err := g.Capture(id); err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	if err := g.Capture(id); err == nil {
+		t.Errorf("expected second capture to fail, got nil")
+	}
+	if err := g.Refund(id); err != nil {
+		t.Fatalf("Refund failed: %v", err)
+	}
+	if err := g.Refund(id); err != nil {
+		t.Errorf("repeated refund failed: %v", err)
+	}
+	if got := g.Payments()[id]; got.Status != Refunded || got.Reference != "T1" || got.Fee != fee {
+		t.Errorf("unexpected payment: %+v", got)
+	}
+
+	g.DeclineNext(1)
+	if _, err := g.Authorize("T2", fee); !errors.Is(err, usecases.ErrPaymentDeclined) {
+		t.Errorf("expected ErrPaymentDeclined, got %v", err)
+	}
+	if _, err := g.Authorize("T2", fee); err != nil {
+		t.Errorf("expected only one decline, got %v", err)
+	}
+
+	g.Limit = 1000
+	if _, err := g.Authorize("T3", fee); !errors.Is(err, usecases.ErrPaymentDeclined) {
+		t.Errorf("expected ErrPaymentDeclined above limit, got %v", err)
+	}
+	if err := g.Capture("PAY-unknown"); err == nil {
+		t.Errorf("expected error for unknown payment, got nil")
+	}
+
+	g.Limit = 0
+	id, _ = g.Authorize("T4", fee)
+	g.FailNextCaptures(1)
+	if err := g.Capture(id); err == nil {
+		t.Errorf("expected queued capture failure, got nil")
+	}
+	if err := g.Void(id); err != nil {
+		t.Fatalf("Void failed: %v", err)
+	}
+	if err := g.Capture(id); err == nil {
+		t.Errorf("expected capture of voided payment to fail, got nil")
+	}
+	if got := g.Payments()[id]; got.Status != Voided {
+		t.Errorf("payment should be voided, got %s", got.Status)
+	}
+}
+
+// failingRecorder refuses events of type Fail until it is cleared.
+type failingRecorder struct {
+	Fail domain.EventType
+}
+
+func (r *failingRecorder) Record(event domain.Event) error {
+	if event.Type == r.Fail {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func TestUnparkWithFakeGateway_Failures(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	engine := &pricing.Engine{Currency: "IDR", Default: &pricing.Tariff{HourlyRate: 3000}}
+	gateway := NewFakeGateway()
+	recorder := &failingRecorder{}
+	u := usecases.NewParkingLotUsecase(1, 1, 1, [][]string{{"A-1"}},
+		usecases.WithClock(func() time.Time { return now }),
+		usecases.WithPricer(engine),
+		usecases.WithPaymentGateway(gateway),
+		usecases.WithEventRecorder(recorder),
+	)
+	ticket, _ := u.Park(constants.Automobile, "CAR123")
+	now = now.Add(time.Hour)
+
+	gateway.FailNextCaptures(1)
+	if _, err := u.UnparkTicket(ticket.ID); err == nil {
+		t.Fatalf("expected capture failure, got nil")
+	}
+	for id, payment := range gateway.Payments() {
+		if payment.Status != Voided {
+			t.Errorf("authorization %s left %s after a failed capture", id, payment.Status)
+		}
+	}
+	receipt, err := u.UnparkTicket(ticket.ID)
+	if err != nil {
+		t.Fatalf("UnparkTicket failed: %v", err)
+	}
+
+	// A refund the lot fails to record is refunded once and marked on retry.
+	recorder.Fail = domain.EventRefunded
+	if err := u.RefundTicket(ticket.ID); err == nil {
+		t.Fatalf("expected refund to fail while events cannot be recorded")
+	}
+	recorder.Fail = ""
+	if err := u.RefundTicket(ticket.ID); err != nil {
+		t.Fatalf("retried RefundTicket failed: %v", err)
+	}
+	if err := u.RefundTicket(ticket.ID); !errors.Is(err, usecases.ErrTicketRefunded) {
+		t.Errorf("third refund = %v, want ErrTicketRefunded", err)
+	}
+	if got := gateway.Payments()[receipt.Ticket.PaymentID]; got.Status != Refunded {
+		t.Errorf("payment should be refunded, got %s", got.Status)
+	}
+}
+
+func TestUnparkWithFakeGateway(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	engine := &pricing.Engine{
+		Currency: "IDR",
+		Tariffs: map[constants.VehicleType]*pricing.Tariff{
+			constants.Automobile: {HourlyRate: 3000},
+			constants.Bicycle:    {GracePeriod: pricing.Duration(time.Hour), HourlyRate: 1000},
+		},
+	}
+	gateway := NewFakeGateway()
+	u := usecases.NewParkingLotUsecase(1, 1, 2, [][]string{{"A-1", "B-1"}},
+		usecases.WithClock(func() time.Time { return now }),
+		usecases.WithPricer(engine),
+		usecases.WithPaymentGateway(gateway),
+	)
+
+	ticket, err := u.Park(constants.Automobile, "CAR123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+
+	gateway.DeclineNext(1)
+	if _, err := u.UnparkTicket(ticket.ID); !errors.Is(err, usecases.ErrPaymentDeclined) {
+		t.Fatalf("expected ErrPaymentDeclined, got %v", err)
+	}
+	if found, _ := u.SearchVehicle("CAR123"); !found.Parked {
+		t.Errorf("declined payment must not release the spot")
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 0 {
+		t.Errorf("declined payment must not free the spot, got %d available", got)
+	}
+
+	receipt, err := u.UnparkTicket(ticket.ID)
+	if err != nil {
+		t.Fatalf("UnparkTicket failed: %v", err)
+	}
+	if receipt.Fee.Amount != 6000 || receipt.Ticket.PaymentID == "" {
+		t.Errorf("unexpected receipt: %+v", receipt)
+	}
+	if got := gateway.Payments()[receipt.Ticket.PaymentID]; got.Status != Captured || got.Fee.Amount != 6000 {
+		t.Errorf("payment should be captured for 6000, got %+v", got)
+	}
+
+	if err := u.RefundTicket(ticket.ID); err != nil {
+		t.Fatalf("RefundTicket failed: %v", err)
+	}
+	if err := u.RefundTicket(ticket.ID); err == nil {
+		t.Errorf("expected second refund to fail, got nil")
+	}
+	if got := gateway.Payments()[receipt.Ticket.PaymentID]; got.Status != Refunded {
+		t.Errorf("payment should be refunded, got %s", got.Status)
+	}
+
+	// Free stays never reach the gateway.
+	bike, err := u.Park(constants.Bicycle, "BIKE123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	gateway.DeclineNext(1)
+	receipt, err = u.UnparkTicket(bike.ID)
+	if err != nil {
+		t.Fatalf("free stay should not need payment, got %v", err)
+	}
+	if receipt.Ticket.PaymentID != "" {
+		t.Errorf("free stay should have no payment, got %s", receipt.Ticket.PaymentID)
+	}
+	if err := u.RefundTicket(bike.ID); err == nil {
+		t.Errorf("expected refund of unpaid ticket to fail, got nil")
+	}
+}
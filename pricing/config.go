@@ -0,0 +1,67 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration written as "15m" or "1h30m" in configuration files.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Load reads a pricing configuration from a JSON or YAML file.
+//
+//	currency: IDR
+//	tariffs:
+//	  B: {flat_fee: 2000}
+//	  A:
+//	    grace_period: 15m
+//	    first_hour: 5000
+//	    hourly_rate: 3000
+//	    daily_cap: 40000
+//	    overnight: {start: "22:00", end: "06:00", hourly_rate: 1000}
+//	default: {hourly_rate: 2000}
+//
+// Types are checked against the registry, so register extra types before loading.
+func Load(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var engine Engine
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &engine)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &engine)
+	default:
+		return nil, fmt.Errorf("unsupported pricing file extension %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode pricing %s: %w", path, err)
+	}
+
+	if err := engine.Validate(); err != nil {
+		return nil, err
+	}
+	return &engine, nil
+}
@@ -0,0 +1,92 @@
+package pricing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+)
+
+func TestLoad(t *testing.T) {
+	files := map[string]string{
+		"pricing.yaml": `currency: IDR
+tariffs:
+  B: {flat_fee: 2000}
+  A:
+    grace_period: 15m
+    first_hour: 5000
+    hourly_rate: 3000
+    daily_cap: 40000
+    overnight: {start: "22:00", end: "06:00", hourly_rate: 1000}
+default: {hourly_rate: 2000}
+`,
+		"pricing.json": `{
+  "currency": "IDR",
+  "tariffs": {
+    "B": {"flat_fee": 2000},
+    "A": {
+      "grace_period": "15m",
+      "first_hour": 5000,
+      "hourly_rate": 3000,
+      "daily_cap": 40000,
+      "overnight": {"start": "22:00", "end": "06:00", "hourly_rate": 1000}
+    }
+  },
+  "default": {"hourly_rate": 2000}
+}`,
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			engine, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if engine.Currency != "IDR" || len(engine.Tariffs) != 2 {
+				t.Fatalf("unexpected engine: %+v", engine)
+			}
+			car := engine.Tariffs[constants.Automobile]
+			if time.Duration(car.GracePeriod) != 15*time.Minute || car.DailyCap != 40000 || car.Overnight.HourlyRate != 1000 {
+				t.Errorf("unexpected car tariff: %+v", car)
+			}
+			if engine.Tariffs[constants.Bicycle].FlatFee != 2000 {
+				t.Errorf("unexpected bicycle tariff: %+v", engine.Tariffs[constants.Bicycle])
+			}
+			if engine.Default == nil || engine.Default.HourlyRate != 2000 {
+				t.Errorf("unexpected default tariff: %+v", engine.Default)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"bad.yaml":     "tariffs:\n  A: {grace_period: soon}\n",
+		"invalid.json": `{"tariffs": {"A": {"hourly_rate": -5}}}`,
+		"missing.yaml": "tariffs:\n  A: {hourly_rate: 3000}\n",
+		"default.yaml": "tariffs:\n  A: {hourly_rate: 3000}\ndefault: {hourly_rate: -1}\n",
+		"pricing.toml": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%s): expected error, got nil", name)
+		}
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Errorf("expected error for missing file, got nil")
+	}
+}
@@ -0,0 +1,181 @@
+package pricing
+
+import (
+	"fmt"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"time"
+)
+
+// Tariff prices a stay for one vehicle type. Amounts are in minor currency units.
+//
+// A stay no longer than GracePeriod is free. Otherwise FlatFee is charged once, and every
+// started hour is charged FirstHour (for the first one, when set) or HourlyRate, or
+// Overnight.HourlyRate when the hour starts inside the overnight window. Hourly charges
+// are summed per 24 hours from entry and each day is capped at DailyCap when set.
+type Tariff struct {
+	GracePeriod Duration   `json:"grace_period,omitempty" yaml:"grace_period,omitempty"`
+	FlatFee     int64      `json:"flat_fee,omitempty" yaml:"flat_fee,omitempty"`
+	FirstHour   int64      `json:"first_hour,omitempty" yaml:"first_hour,omitempty"`
+	HourlyRate  int64      `json:"hourly_rate,omitempty" yaml:"hourly_rate,omitempty"`
+	DailyCap    int64      `json:"daily_cap,omitempty" yaml:"daily_cap,omitempty"`
+	Overnight   *Overnight `json:"overnight,omitempty" yaml:"overnight,omitempty"`
+}
+
+// Overnight is a cheaper hourly rate for hours starting between Start and End, given as
+// "HH:MM" wall-clock times in the location of the entry time. The window may wrap midnight.
+type Overnight struct {
+	Start      string `json:"start" yaml:"start"`
+	End        string `json:"end" yaml:"end"`
+	HourlyRate int64  `json:"hourly_rate" yaml:"hourly_rate"`
+}
+
+// window returns the start and end of the overnight window in minutes since midnight.
+func (o *Overnight) window() (int, int, error) {
+	start, err := parseClock(o.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseClock(o.End)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+func inWindow(t time.Time, start, end int) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (t *Tariff) validate() error {
+	for name, amount := range map[string]int64{
+		"flat_fee":    t.FlatFee,
+		"first_hour":  t.FirstHour,
+		"hourly_rate": t.HourlyRate,
+		"daily_cap":   t.DailyCap,
+	} {
+		if amount < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	if t.GracePeriod < 0 {
+		return fmt.Errorf("grace_period must not be negative")
+	}
+	if t.Overnight != nil {
+		if t.Overnight.HourlyRate < 0 {
+			return fmt.Errorf("overnight hourly_rate must not be negative")
+		}
+		if _, _, err := t.Overnight.window(); err != nil {
+			return fmt.Errorf("overnight: %w", err)
+		}
+	}
+	return nil
+}
+
+// Price returns the charge for a stay from entry to exit. An overnight window that does
+// not parse is ignored; Engine.Validate reports it.
+func (t *Tariff) Price(entry, exit time.Time) int64 {
+	stay := exit.Sub(entry)
+	if stay <= time.Duration(t.GracePeriod) {
+		return 0
+	}
+
+	overnight := t.Overnight != nil
+	var nightStart, nightEnd int
+	if overnight {
+		var err error
+		nightStart, nightEnd, err = t.Overnight.window()
+		overnight = err == nil
+	}
+
+	hours := int((stay + time.Hour - 1) / time.Hour)
+	total := t.FlatFee
+	var day int64
+	for h := 0; h < hours; h++ {
+		if h > 0 && h%24 == 0 {
+			total += t.capDay(day)
+			day = 0
+		}
+
+		start := entry.Add(time.Duration(h) * time.Hour)
+		switch {
+		case h == 0 && t.FirstHour > 0:
+			day += t.FirstHour
+		case overnight && inWindow(start, nightStart, nightEnd):
+			day += t.Overnight.HourlyRate
+		default:
+			day += t.HourlyRate
+		}
+	}
+	return total + t.capDay(day)
+}
+
+func (t *Tariff) capDay(amount int64) int64 {
+	if t.DailyCap > 0 && amount > t.DailyCap {
+		return t.DailyCap
+	}
+	return amount
+}
+
+// Engine prices closed tickets using the tariff of their vehicle type, or Default for
+// types without one of their own.
+type Engine struct {
+	Currency string                            `json:"currency" yaml:"currency"`
+	Tariffs  map[constants.VehicleType]*Tariff `json:"tariffs" yaml:"tariffs"`
+	Default  *Tariff                           `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// Validate checks every tariff and that each registered vehicle type can be priced, so no
+// vehicle is refused at the exit gate for want of a tariff. Types registered later are
+// priced with Default.
+func (e *Engine) Validate() error {
+	if e.Default != nil {
+		if err := e.Default.validate(); err != nil {
+			return fmt.Errorf("default tariff: %w", err)
+		}
+	} else {
+		for _, vt := range constants.Types() {
+			if _, ok := e.Tariffs[vt]; !ok {
+				return fmt.Errorf("no tariff for vehicle type %s and no default tariff", vt)
+			}
+		}
+	}
+	for vt, tariff := range e.Tariffs {
+		if tariff == nil {
+			return fmt.Errorf("tariff %s: empty", vt)
+		}
+		if err := tariff.validate(); err != nil {
+			return fmt.Errorf("tariff %s: %w", vt, err)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) Price(ticket domain.Ticket) (domain.Fee, error) {
+	tariff, ok := e.Tariffs[ticket.VehicleType]
+	if !ok {
+		tariff = e.Default
+	}
+	if tariff == nil {
+		return domain.Fee{}, fmt.Errorf("no tariff for vehicle type %s", ticket.VehicleType)
+	}
+	if ticket.Active() {
+		return domain.Fee{}, fmt.Errorf("ticket %s is still open", ticket.ID)
+	}
+	return domain.Fee{
+		Amount:   tariff.Price(ticket.EntryTime, ticket.ExitTime),
+		Currency: e.Currency,
+	}, nil
+}
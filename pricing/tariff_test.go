@@ -0,0 +1,102 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+)
+
+func TestTariffPrice(t *testing.T) {
+	entry := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		tariff   Tariff
+		stay     time.Duration
+		expected int64
+	}{
+		{"Hourly", Tariff{HourlyRate: 3000}, 2 * time.Hour, 6000},
+		{"StartedHourRoundsUp", Tariff{HourlyRate: 3000}, 61 * time.Minute, 6000},
+		{"FirstHour", Tariff{FirstHour: 5000, HourlyRate: 3000}, 3 * time.Hour, 11000},
+		{"FlatFeeOnly", Tariff{FlatFee: 2000}, 10 * time.Hour, 2000},
+		{"FlatFeePlusHourly", Tariff{FlatFee: 1000, HourlyRate: 500}, 2 * time.Hour, 2000},
+		{"WithinGrace", Tariff{GracePeriod: Duration(15 * time.Minute), HourlyRate: 3000}, 15 * time.Minute, 0},
+		{"PastGrace", Tariff{GracePeriod: Duration(15 * time.Minute), HourlyRate: 3000}, 16 * time.Minute, 3000},
+		{"DailyCap", Tariff{HourlyRate: 3000, DailyCap: 40000}, 20 * time.Hour, 40000},
+		{"DailyCapPerDay", Tariff{HourlyRate: 3000, DailyCap: 40000}, 26 * time.Hour, 46000},
+		{"Overnight", Tariff{HourlyRate: 3000, Overnight: &Overnight{Start: "22:00", End: "06:00", HourlyRate: 1000}}, 24 * time.Hour, 16*3000 + 8*1000},
+		{"OvernightDaytimeWindow", Tariff{HourlyRate: 3000, Overnight: &Overnight{Start: "10:00", End: "12:00", HourlyRate: 0}}, 4 * time.Hour, 6000},
+		{"ZeroStayIsFree", Tariff{FlatFee: 2000}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tariff.Price(entry, entry.Add(tt.stay)); got != tt.expected {
+				t.Errorf("Tariff.Price() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEngine_Price(t *testing.T) {
+	entry := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	engine := &Engine{
+		Currency: "IDR",
+		Tariffs: map[constants.VehicleType]*Tariff{
+			constants.Automobile: {HourlyRate: 3000},
+		},
+	}
+
+	fee, err := engine.Price(domain.Ticket{VehicleType: constants.Automobile, EntryTime: entry, ExitTime: entry.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Price failed: %v", err)
+	}
+	if fee.Amount != 3000 || fee.Currency != "IDR" {
+		t.Errorf("Price = %+v, want 3000 IDR", fee)
+	}
+
+	if _, err := engine.Price(domain.Ticket{VehicleType: constants.Bicycle, EntryTime: entry, ExitTime: entry}); err == nil {
+		t.Errorf("expected error for vehicle type without tariff, got nil")
+	}
+	engine.Default = &Tariff{FlatFee: 1000}
+	if fee, err := engine.Price(domain.Ticket{VehicleType: constants.Bicycle, EntryTime: entry, ExitTime: entry.Add(time.Hour)}); err != nil || fee.Amount != 1000 {
+		t.Errorf("Price with default tariff = %+v, %v", fee, err)
+	}
+	if _, err := engine.Price(domain.Ticket{VehicleType: constants.Automobile, EntryTime: entry}); err == nil {
+		t.Errorf("expected error for open ticket, got nil")
+	}
+}
+
+func TestEngine_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		tariff *Tariff
+	}{
+		{"NegativeRate", &Tariff{HourlyRate: -1}},
+		{"NegativeGrace", &Tariff{GracePeriod: Duration(-time.Minute)}},
+		{"BadOvernight", &Tariff{Overnight: &Overnight{Start: "25:00", End: "06:00"}}},
+		{"Nil", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &Engine{Tariffs: map[constants.VehicleType]*Tariff{constants.Automobile: tt.tariff}, Default: &Tariff{}}
+			if err := engine.Validate(); err == nil {
+				t.Errorf("expected validation error, got nil")
+			}
+		})
+	}
+
+	engine := &Engine{Tariffs: map[constants.VehicleType]*Tariff{constants.Automobile: {}}}
+	if err := engine.Validate(); err == nil {
+		t.Errorf("expected error for registered types without a tariff, got nil")
+	}
+	for _, vt := range constants.Types() {
+		engine.Tariffs[vt] = &Tariff{}
+	}
+	if err := engine.Validate(); err != nil {
+		t.Errorf("Validate with every type priced: %v", err)
+	}
+}
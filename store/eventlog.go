@@ -0,0 +1,302 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"submit_do_it/domain"
+	"sync"
+	"time"
+)
+
+// SyncPolicy decides when appended events are forced to disk.
+type SyncPolicy int
+
+const (
+	SyncAlways   SyncPolicy = iota // fsync before Record returns; nothing acknowledged is lost
+	SyncInterval                   // fsync in the background; a crash loses at most one interval
+	SyncNever                      // leave flushing to the operating system
+)
+
+// CorruptLogError reports a damaged record followed by intact ones. Unlike a torn final
+// record, which a crash during an append leaves behind, this cannot be repaired safely.
+type CorruptLogError struct {
+	Offset int64
+	Reason string
+}
+
+func (e *CorruptLogError) Error() string {
+	return fmt.Sprintf("event log corrupt at offset %d: %s", e.Offset, e.Reason)
+}
+
+// EventLog is an append-only file of events, one per line as "CRC32 JSON", where the
+// checksum covers the JSON. It implements usecases.EventRecorder and is safe for
+// concurrent use.
+type EventLog struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	policy  SyncPolicy
+	dirty   bool
+	dropped int64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// OpenEventLog opens or creates the log at path and returns the events it holds. A torn
+// or corrupt final record is cut off; see Dropped. interval is only used by SyncInterval.
+func OpenEventLog(path string, policy SyncPolicy, interval time.Duration) (*EventLog, []domain.Event, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, valid, err := ReadEvents(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if valid < size {
+		if err := truncate(f, valid); err != nil {
+			f.Close()
+			return nil, nil, fmt.Errorf("cut torn record: %w", err)
+		}
+	}
+
+	l := &EventLog{path: path, f: f, policy: policy, dropped: size - valid, done: make(chan struct{})}
+	if policy == SyncInterval {
+		if interval <= 0 {
+			f.Close()
+			return nil, nil, errors.New("sync interval must be positive")
+		}
+		l.wg.Add(1)
+		go l.syncLoop(interval)
+	}
+	return l, events, nil
+}
+
+func truncate(f *os.File, size int64) error {
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// ReadEvents decodes every intact record and returns the length of the valid prefix. A
+// damaged record is tolerated only at the end of the log; one followed by an intact
+// record yields a *CorruptLogError.
+func ReadEvents(r io.Reader) ([]domain.Event, int64, error) {
+	var (
+		events []domain.Event
+		offset int64
+		valid  int64
+		bad    *CorruptLogError
+	)
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+
+		event, reason := decodeRecord(line)
+		switch {
+		case reason != "" && bad == nil:
+			bad = &CorruptLogError{Offset: offset, Reason: reason}
+		case reason == "" && bad != nil:
+			return nil, 0, bad
+		case reason == "":
+			events = append(events, event)
+			valid = offset + int64(len(line))
+		}
+		offset += int64(len(line))
+	}
+	return events, valid, nil
+}
+
+func decodeRecord(line []byte) (domain.Event, string) {
+	var event domain.Event
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		return event, "truncated record"
+	}
+
+	var sum uint32
+	sumHex, payload, ok := bytes.Cut(bytes.TrimSuffix(line, []byte("\n")), []byte(" "))
+	if !ok || len(sumHex) != 8 {
+		return event, "malformed record"
+	}
+	if _, err := fmt.Sscanf(string(sumHex), "%08x", &sum); err != nil {
+		return event, "malformed checksum"
+	}
+	if crc32.ChecksumIEEE(payload) != sum {
+		return event, "checksum mismatch"
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return event, "undecodable event: " + err.Error()
+	}
+	return event, ""
+}
+
+func encodeRecord(event domain.Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, "%08x %s\n", crc32.ChecksumIEEE(payload), payload), nil
+}
+
+// Record appends an event, syncing it according to the log's policy.
+func (l *EventLog) Record(event domain.Event) error {
+	record, err := encodeRecord(event)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.f.Write(record); err != nil {
+		return err
+	}
+	if l.policy == SyncAlways {
+		return l.f.Sync()
+	}
+	l.dirty = true
+	return nil
+}
+
+// Sync forces every recorded event to disk.
+func (l *EventLog) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.syncLocked()
+}
+
+func (l *EventLog) syncLocked() error {
+	if !l.dirty {
+		return nil
+	}
+	if err := l.f.Sync(); err != nil {
+		return err
+	}
+	l.dirty = false
+	return nil
+}
+
+func (l *EventLog) syncLoop(interval time.Duration) {
+	defer l.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.Sync()
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// Dropped returns how many bytes of torn or corrupt records OpenEventLog cut off.
+func (l *EventLog) Dropped() int64 {
+	return l.dropped
+}
+
+// Compact saves snap to snapshotPath and rewrites the log without the events the snapshot
+// already covers. Events recorded while the snapshot is written are kept. A crash at any
+// point leaves a snapshot and log that replay to the same lot.
+func (l *EventLog) Compact(snapshotPath string, snap *domain.Snapshot) error {
+	if err := SaveSnapshot(snapshotPath, snap); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return err
+	}
+	events, _, err := ReadEvents(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	w := bufio.NewWriter(tmp)
+	for _, event := range events {
+		if event.Seq <= snap.EventSeq {
+			continue
+		}
+		record, err := encodeRecord(event)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(record)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	l.f.Close()
+	l.f = tmp
+	l.dirty = false
+	if err := syncDir(filepath.Dir(l.path)); err != nil {
+		return err
+	}
+	_, err = l.f.Seek(0, io.SeekEnd)
+	return err
+}
+
+// Close stops background syncing, syncs once more and closes the file.
+func (l *EventLog) Close() error {
+	close(l.done)
+	l.wg.Wait()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.syncLocked(); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/usecases"
+)
+
+// openLot recovers a lot from the snapshot and log in dir, creating a 1x3 lot the first time.
+func openLot(t *testing.T, dir string) (usecases.ParkinglotUsecase, *EventLog) {
+	t.Helper()
+	snapPath := filepath.Join(dir, "lot.json")
+	snap, err := LoadSnapshot(snapPath)
+	if errors.Is(err, os.ErrNotExist) {
+		snap = usecases.NewParkingLotUsecase(1, 1, 3, [][]string{{"B-1", "A-1", "A-1"}}).Snapshot()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	log, events, err := OpenEventLog(filepath.Join(dir, "events.log"), SyncAlways, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	u, err := usecases.NewParkingLotUsecaseFromEvents(snap, events, usecases.WithEventRecorder(log))
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	return u, log
+}
+
+func TestEventLog_ReplayAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	u, _ := openLot(t, dir)
+	ticket, _ := u.Park(constants.Automobile, "CAR1")
+	u.Park(constants.Automobile, "CAR2")
+	if _, err := u.UnparkTicket(ticket.ID); err != nil {
+		t.Fatal(err)
+	}
+	u.Park(constants.Bicycle, "BIKE1")
+
+	// No Close or snapshot: the process died.
+	recovered, _ := openLot(t, dir)
+	if got := recovered.AvailableSpot(constants.Automobile); got != 1 {
+		t.Errorf("free A spots = %d, want 1", got)
+	}
+	if got := recovered.AvailableSpot(constants.Bicycle); got != 0 {
+		t.Errorf("free B spots = %d, want 0", got)
+	}
+	if loc, err := recovered.SearchVehicle("CAR1"); err != nil || loc.Parked {
+		t.Errorf("CAR1 = %+v, %v, want unparked", loc, err)
+	}
+	next, err := recovered.Park(constants.Automobile, "CAR3")
+	if err != nil || next.ID != "T00000004" || next.SpotID != "0-0-1" {
+		t.Errorf("park after recovery = %+v, %v", next, err)
+	}
+}
+
+func TestEventLog_TornTail(t *testing.T) {
+	dir := t.TempDir()
+	u, _ := openLot(t, dir)
+	u.Park(constants.Automobile, "CAR1")
+	u.Park(constants.Automobile, "CAR2")
+
+	path := filepath.Join(dir, "events.log")
+	data, _ := os.ReadFile(path)
+	torn := data[:len(data)-10]
+	os.WriteFile(path, torn, 0o644)
+
+	recovered, log := openLot(t, dir)
+	if log.Dropped() == 0 {
+		t.Error("expected torn record to be dropped")
+	}
+	if _, err := recovered.SearchVehicle("CAR2"); !errors.Is(err, usecases.ErrVehicleNotFound) {
+		t.Errorf("CAR2 survived a torn record: %v", err)
+	}
+	if _, err := recovered.Park(constants.Automobile, "CAR3"); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered, _ = openLot(t, dir)
+	if loc, err := recovered.SearchVehicle("CAR3"); err != nil || !loc.Parked {
+		t.Errorf("CAR3 after second recovery = %+v, %v", loc, err)
+	}
+}
+
+func TestEventLog_CorruptMiddle(t *testing.T) {
+	dir := t.TempDir()
+	u, _ := openLot(t, dir)
+	u.Park(constants.Automobile, "CAR1")
+	u.Park(constants.Automobile, "CAR2")
+
+	path := filepath.Join(dir, "events.log")
+	data, _ := os.ReadFile(path)
+	data[12] ^= 0xff
+	os.WriteFile(path, data, 0o644)
+
+	_, _, err := OpenEventLog(path, SyncAlways, 0)
+	var corrupt *CorruptLogError
+	if !errors.As(err, &corrupt) || corrupt.Offset != 0 {
+		t.Errorf("err = %v, want CorruptLogError at offset 0", err)
+	}
+}
+
+func TestEventLog_Compact(t *testing.T) {
+	dir := t.TempDir()
+	u, log := openLot(t, dir)
+	u.Park(constants.Automobile, "CAR1")
+	u.Park(constants.Automobile, "CAR2")
+
+	snap := u.Snapshot()
+	u.Park(constants.Bicycle, "BIKE1") // recorded after the snapshot was taken
+	if err := log.Compact(filepath.Join(dir, "lot.json"), snap); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "events.log"))
+	events, _, err := ReadEvents(bytes.NewReader(data))
+	if err != nil || len(events) != 1 || events[0].Seq != 3 {
+		t.Fatalf("compacted log = %+v, %v, want only event 3", events, err)
+	}
+
+	u.Park(constants.Automobile, "CAR3")
+	if err := log.Compact(filepath.Join(dir, "lot.json"), u.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	recovered, _ := openLot(t, dir)
+	for _, vt := range constants.Types() {
+		if got := recovered.AvailableSpot(vt); got != 0 {
+			t.Errorf("free %s spots = %d, want 0", vt, got)
+		}
+	}
+}
+
+func TestEventLog_SyncPolicies(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncAlways, SyncInterval, SyncNever} {
+		path := filepath.Join(t.TempDir(), "events.log")
+		log, _, err := OpenEventLog(path, policy, time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := log.Record(domain.Event{Seq: 1, Type: domain.EventParked}); err != nil {
+			t.Fatal(err)
+		}
+		if err := log.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		reopened, events, err := OpenEventLog(path, SyncNever, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reopened.Close()
+		if len(events) != 1 {
+			t.Errorf("policy %d: reopened %d event(s), want 1", policy, len(events))
+		}
+	}
+
+	if _, _, err := OpenEventLog(filepath.Join(t.TempDir(), "events.log"), SyncInterval, 0); err == nil {
+		t.Error("expected error for zero sync interval")
+	}
+}
@@ -0,0 +1,79 @@
+// Package store keeps parking lot state on disk.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"submit_do_it/domain"
+)
+
+// WriteSnapshot encodes snap as indented JSON.
+func WriteSnapshot(w io.Writer, snap *domain.Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snap)
+}
+
+// ReadSnapshot decodes a snapshot, rejecting versions this build cannot restore.
+func ReadSnapshot(r io.Reader) (*domain.Snapshot, error) {
+	var snap domain.Snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if snap.Version != domain.SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d, want %d", snap.Version, domain.SnapshotVersion)
+	}
+	return &snap, nil
+}
+
+// SaveSnapshot writes snap to path atomically: it is written and synced to a temporary
+// file in the same directory, then renamed over path and the directory synced, so a crash
+// leaves either the old or the new snapshot.
+func SaveSnapshot(path string, snap *domain.Snapshot) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteSnapshot(tmp, snap); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir flushes a directory, making a rename into it durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+func LoadSnapshot(path string) (*domain.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSnapshot(f)
+}
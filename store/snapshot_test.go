@@ -0,0 +1,60 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/usecases"
+)
+
+func TestSaveLoadSnapshot(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	u := usecases.NewParkingLotUsecase(1, 1, 3, [][]string{{"B-1", "#", "A-1"}}, usecases.WithClock(func() time.Time { return clock }))
+	ticket, _ := u.Park(constants.Automobile, "CAR1")
+	clock = clock.Add(time.Hour)
+	u.UnparkTicket(ticket.ID)
+	u.Park(constants.Bicycle, "BIKE1")
+	snap := u.Snapshot()
+
+	path := filepath.Join(t.TempDir(), "lot.json")
+	if err := SaveSnapshot(path, snap); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap.Parked = nil // derived from the spots, not saved
+	if !reflect.DeepEqual(got, snap) {
+		t.Errorf("loaded snapshot differs:\n got %+v\nwant %+v", got, snap)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestReadSnapshot_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"Malformed", `{"version":`, "decode snapshot"},
+		{"FutureVersion", `{"version":2,"floors":[]}`, "unsupported snapshot version 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSnapshot(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/usecases"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS spots (
+	floor INTEGER NOT NULL,
+	row   INTEGER NOT NULL,
+	col   INTEGER NOT NULL,
+	code  TEXT    NOT NULL,
+	PRIMARY KEY (floor, row, col)
+);
+CREATE TABLE IF NOT EXISTS tickets (
+	seq            INTEGER PRIMARY KEY,
+	id             TEXT    NOT NULL UNIQUE,
+	vehicle_number TEXT    NOT NULL,
+	vehicle_type   TEXT    NOT NULL,
+	spot_id        TEXT    NOT NULL,
+	spot_type      TEXT    NOT NULL DEFAULT '',
+	spot_count     INTEGER NOT NULL DEFAULT 0,
+	entry_time     TEXT    NOT NULL,
+	exit_time      TEXT,
+	fee_amount     INTEGER NOT NULL DEFAULT 0,
+	fee_currency   TEXT    NOT NULL DEFAULT '',
+	payment_id     TEXT    NOT NULL DEFAULT '',
+	refunded       INTEGER NOT NULL DEFAULT 0,
+	reservation_id TEXT    NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS tickets_open_vehicle ON tickets (vehicle_number) WHERE exit_time IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS tickets_open_spot ON tickets (spot_id) WHERE exit_time IS NULL;
+CREATE INDEX IF NOT EXISTS tickets_history ON tickets (vehicle_number, seq);
+CREATE TABLE IF NOT EXISTS reservations (
+	seq            INTEGER PRIMARY KEY,
+	id             TEXT    NOT NULL UNIQUE,
+	vehicle_number TEXT    NOT NULL,
+	vehicle_type   TEXT    NOT NULL,
+	spot_type      TEXT    NOT NULL,
+	spot_count     INTEGER NOT NULL DEFAULT 0,
+	start_time     TEXT    NOT NULL,
+	end_time       TEXT    NOT NULL,
+	status         TEXT    NOT NULL,
+	spot_id        TEXT    NOT NULL DEFAULT '',
+	waitlisted     INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS reservations_open ON reservations (status) WHERE status IN ('booked', 'held');
+`
+
+const ticketColumns = `id, vehicle_number, vehicle_type, spot_id, spot_type, spot_count, entry_time, exit_time, fee_amount, fee_currency, payment_id, refunded, reservation_id`
+
+const reservationColumns = `id, vehicle_number, vehicle_type, spot_type, spot_count, start_time, end_time, status, spot_id, waitlisted`
+
+// migrations bring databases created by earlier versions up to schema. Each runs only if
+// its column is missing.
+var migrations = []struct {
+	table, column, ddl string
+}{
+	{"tickets", "spot_type", `ALTER TABLE tickets ADD COLUMN spot_type TEXT NOT NULL DEFAULT ''`},
+	{"tickets", "spot_count", `ALTER TABLE tickets ADD COLUMN spot_count INTEGER NOT NULL DEFAULT 0`},
+	{"tickets", "reservation_id", `ALTER TABLE tickets ADD COLUMN reservation_id TEXT NOT NULL DEFAULT ''`},
+	{"reservations", "waitlisted", `ALTER TABLE reservations ADD COLUMN waitlisted INTEGER NOT NULL DEFAULT 0`},
+}
+
+// SQLiteRepository is a usecases.ParkingRepository in an embedded SQLite database. Open
+// tickets are rows without an exit time; unique indexes keep a vehicle or spot from having
+// two of them. Only the first spot of a vehicle taking several is indexed; the lot keeps
+// the others from being handed out twice.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens or creates the database at path. A new database has no layout until
+// InitLayout is called.
+func OpenSQLite(path string) (*SQLiteRepository, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=synchronous(FULL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	// A single connection serializes transactions, so SaveParked's check and insert
+	// cannot interleave with another writer in this process.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteRepository{db: db}, nil
+}
+
+// migrate adds the columns missing from an existing database, then creates whatever else
+// is missing.
+func migrate(db *sql.DB) error {
+	for _, m := range migrations {
+		var tables int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, m.table).Scan(&tables); err != nil {
+			return err
+		}
+		var columns int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, m.table, m.column).Scan(&columns); err != nil {
+			return err
+		}
+		if tables == 0 || columns > 0 {
+			continue
+		}
+		if _, err := db.Exec(m.ddl); err != nil {
+			return fmt.Errorf("add %s.%s: %w", m.table, m.column, err)
+		}
+	}
+	if _, err := db.Exec(schema); err != nil {
+		return fmt.Errorf("create schema: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// InitLayout stores the layout of a new lot. It fails if the database already has one.
+func (r *SQLiteRepository) InitLayout(floors [][][]string) error {
+	if _, err := domain.ParseLayout(floors); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var n int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM spots`).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return errors.New("layout already stored")
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO spots (floor, row, col, code) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for f, floor := range floors {
+		for row, codes := range floor {
+			for c, code := range codes {
+				if _, err := stmt.Exec(f, row, c, code); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Layout returns the stored layout, or nil if InitLayout was never called.
+func (r *SQLiteRepository) Layout() ([][][]string, error) {
+	rows, err := r.db.Query(`SELECT floor, row, col, code FROM spots ORDER BY floor, row, col`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var floors [][][]string
+	for rows.Next() {
+		var (
+			f, row, c int
+			code      string
+		)
+		if err := rows.Scan(&f, &row, &c, &code); err != nil {
+			return nil, err
+		}
+		for len(floors) <= f {
+			floors = append(floors, nil)
+		}
+		for len(floors[f]) <= row {
+			floors[f] = append(floors[f], nil)
+		}
+		if c != len(floors[f][row]) {
+			return nil, fmt.Errorf("spot %d-%d-%d missing from layout", f, row, len(floors[f][row]))
+		}
+		floors[f][row] = append(floors[f][row], code)
+	}
+	return floors, rows.Err()
+}
+
+func (r *SQLiteRepository) TicketSeq() (uint64, error) {
+	var seq uint64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM tickets`).Scan(&seq)
+	return seq, err
+}
+
+func (r *SQLiteRepository) OpenTickets() ([]domain.Ticket, error) {
+	return r.queryTickets(`SELECT ` + ticketColumns + ` FROM tickets WHERE exit_time IS NULL ORDER BY seq`)
+}
+
+func (r *SQLiteRepository) Ticket(id string) (domain.Ticket, error) {
+	tickets, err := r.queryTickets(`SELECT `+ticketColumns+` FROM tickets WHERE id = ?`, id)
+	if err != nil {
+		return domain.Ticket{}, err
+	}
+	if len(tickets) == 0 {
+		return domain.Ticket{}, usecases.ErrTicketNotFound
+	}
+	return tickets[0], nil
+}
+
+func (r *SQLiteRepository) OpenTicket(vehicleNumber string) (domain.Ticket, error) {
+	tickets, err := r.queryTickets(`SELECT `+ticketColumns+` FROM tickets WHERE vehicle_number = ? AND exit_time IS NULL`, vehicleNumber)
+	if err != nil {
+		return domain.Ticket{}, err
+	}
+	if len(tickets) == 0 {
+		return domain.Ticket{}, usecases.ErrVehicleNotFound
+	}
+	return tickets[0], nil
+}
+
+func (r *SQLiteRepository) History(vehicleNumber string) ([]domain.Ticket, error) {
+	return r.queryTickets(`SELECT `+ticketColumns+` FROM tickets WHERE vehicle_number = ? ORDER BY seq`, vehicleNumber)
+}
+
+func (r *SQLiteRepository) SaveParked(ticket domain.Ticket) error {
+	seq, err := domain.TicketNumber(ticket.ID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var open int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM tickets WHERE vehicle_number = ? AND exit_time IS NULL`, ticket.VehicleNumber).Scan(&open); err != nil {
+		return err
+	}
+	if open > 0 {
+		return usecases.ErrAlreadyParked
+	}
+
+	_, err = tx.Exec(`INSERT INTO tickets (seq, `+ticketColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULL, 0, '', '', 0, ?)`,
+		seq, ticket.ID, ticket.VehicleNumber, string(ticket.VehicleType), ticket.SpotID, string(ticket.SpotType), ticket.SpotCount, formatTime(ticket.EntryTime), ticket.ReservationID)
+	if err != nil {
+		return err
+	}
+	if ticket.ReservationID != "" {
+		res, err := tx.Exec(`UPDATE reservations SET status = ? WHERE id = ? AND status = ?`,
+			domain.ReservationFulfilled, ticket.ReservationID, domain.ReservationHeld)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("reservation %s holds no spot", ticket.ReservationID)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteRepository) SaveUnparked(ticket domain.Ticket) error {
+	res, err := r.db.Exec(`UPDATE tickets SET exit_time = ?, fee_amount = ?, fee_currency = ?, payment_id = ? WHERE id = ? AND exit_time IS NULL`,
+		formatTime(ticket.ExitTime), ticket.Fee.Amount, ticket.Fee.Currency, ticket.PaymentID, ticket.ID)
+	if err != nil {
+		return err
+	}
+	return r.checkUpdated(res, ticket.ID, usecases.ErrTicketClosed)
+}
+
+func (r *SQLiteRepository) SaveRefunded(ticketID string) error {
+	res, err := r.db.Exec(`UPDATE tickets SET refunded = 1 WHERE id = ? AND refunded = 0`, ticketID)
+	if err != nil {
+		return err
+	}
+	return r.checkUpdated(res, ticketID, usecases.ErrTicketRefunded)
+}
+
+func (r *SQLiteRepository) SaveActive(spotIDs []string, active bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, spotID := range spotIDs {
+		id, err := domain.ParseSpotID(spotID)
+		if err != nil {
+			return err
+		}
+		var code string
+		err = tx.QueryRow(`SELECT code FROM spots WHERE floor = ? AND row = ? AND col = ?`, id.Floor, id.Row, id.Col).Scan(&code)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("spot %s not in layout", spotID)
+		}
+		if err != nil {
+			return err
+		}
+		spotType, _, err := domain.ParseSpotCode(code)
+		if err != nil {
+			return fmt.Errorf("spot %s: %w", spotID, err)
+		}
+		spot := domain.Spot{SpotType: spotType, Active: active}
+		if _, err := tx.Exec(`UPDATE spots SET code = ? WHERE floor = ? AND row = ? AND col = ?`, spot.Code(), id.Floor, id.Row, id.Col); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteRepository) ReservationSeq() (uint64, error) {
+	var seq uint64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM reservations`).Scan(&seq)
+	return seq, err
+}
+
+func (r *SQLiteRepository) OpenReservations() ([]domain.Reservation, error) {
+	rows, err := r.db.Query(`SELECT `+reservationColumns+` FROM reservations WHERE status IN (?, ?) ORDER BY seq`,
+		domain.ReservationBooked, domain.ReservationHeld)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []domain.Reservation
+	for rows.Next() {
+		var (
+			res                   domain.Reservation
+			vehicleType, spotType string
+			start, end, status    string
+		)
+		if err := rows.Scan(&res.ID, &res.VehicleNumber, &vehicleType, &spotType, &res.SpotCount, &start, &end, &status, &res.SpotID, &res.Waitlisted); err != nil {
+			return nil, err
+		}
+		res.VehicleType = constants.VehicleType(vehicleType)
+		res.SpotType = constants.VehicleType(spotType)
+		res.Status = domain.ReservationStatus(status)
+		if res.Start, err = time.Parse(time.RFC3339Nano, start); err != nil {
+			return nil, fmt.Errorf("reservation %s: %w", res.ID, err)
+		}
+		if res.End, err = time.Parse(time.RFC3339Nano, end); err != nil {
+			return nil, fmt.Errorf("reservation %s: %w", res.ID, err)
+		}
+		reservations = append(reservations, res)
+	}
+	return reservations, rows.Err()
+}
+
+// SaveReservation inserts a new reservation or updates the status and held spot of a
+// stored one; its vehicle, type and window never change.
+func (r *SQLiteRepository) SaveReservation(res domain.Reservation) error {
+	seq, err := domain.ReservationNumber(res.ID)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`INSERT INTO reservations (seq, `+reservationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET status = excluded.status, spot_id = excluded.spot_id`,
+		seq, res.ID, res.VehicleNumber, string(res.VehicleType), string(res.SpotType), res.SpotCount,
+		formatTime(res.Start), formatTime(res.End), string(res.Status), res.SpotID, res.Waitlisted)
+	return err
+}
+
+// checkUpdated tells a missing ticket from one whose state did not allow the update.
+func (r *SQLiteRepository) checkUpdated(res sql.Result, ticketID string, stateErr error) error {
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	if _, err := r.Ticket(ticketID); err != nil {
+		return err
+	}
+	return stateErr
+}
+
+func (r *SQLiteRepository) queryTickets(query string, args ...any) ([]domain.Ticket, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickets []domain.Ticket
+	for rows.Next() {
+		var (
+			t           domain.Ticket
+			vehicleType string
+			spotType    string
+			entry       string
+			exit        sql.NullString
+		)
+		err := rows.Scan(&t.ID, &t.VehicleNumber, &vehicleType, &t.SpotID, &spotType, &t.SpotCount, &entry, &exit,
+			&t.Fee.Amount, &t.Fee.Currency, &t.PaymentID, &t.Refunded, &t.ReservationID)
+		if err != nil {
+			return nil, err
+		}
+		t.VehicleType = constants.VehicleType(vehicleType)
+		t.SpotType = constants.VehicleType(spotType)
+		if t.EntryTime, err = time.Parse(time.RFC3339Nano, entry); err != nil {
+			return nil, fmt.Errorf("ticket %s: %w", t.ID, err)
+		}
+		if exit.Valid {
+			if t.ExitTime, err = time.Parse(time.RFC3339Nano, exit.String); err != nil {
+				return nil, fmt.Errorf("ticket %s: %w", t.ID, err)
+			}
+		}
+		tickets = append(tickets, t)
+	}
+	return tickets, rows.Err()
+}
+
+// formatTime keeps the UTC offset of t, so wall-clock pricing rules such as overnight
+// rates see the same local time after a round trip.
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
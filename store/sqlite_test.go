@@ -0,0 +1,397 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"submit_do_it/layout"
+	"submit_do_it/payment"
+	"submit_do_it/pricing"
+	"submit_do_it/usecases"
+)
+
+var sqliteFloors = [][][]string{
+	{{"B-1", "A-1", "A-1"}},
+	{{"#", "A-1", "M-0"}, {"B-1", ".", "A-1"}},
+}
+
+func openSQLiteLot(t *testing.T, path string, opts ...usecases.Option) usecases.ParkinglotUsecase {
+	t.Helper()
+	repo, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	if floors, err := repo.Layout(); err != nil {
+		t.Fatal(err)
+	} else if floors == nil {
+		if err := repo.InitLayout(sqliteFloors); err != nil {
+			t.Fatal(err)
+		}
+	}
+	u, err := usecases.NewParkingLotUsecaseFromRepository(repo, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestSQLiteRepository_Usecase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lot.db")
+	clock := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	gateway := payment.NewFakeGateway()
+	u := openSQLiteLot(t, path,
+		usecases.WithClock(func() time.Time { return clock }),
+		usecases.WithPricer(flatPricer{}),
+		usecases.WithPaymentGateway(gateway))
+
+	first, err := u.Park(constants.Automobile, "CAR1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Park(constants.Automobile, "CAR1"); !errors.Is(err, usecases.ErrAlreadyParked) {
+		t.Errorf("park twice: %v, want ErrAlreadyParked", err)
+	}
+	u.Park(constants.Automobile, "CAR2")
+	u.Park(constants.Bicycle, "BIKE1")
+
+	clock = clock.Add(2 * time.Hour)
+	receipt, err := u.Unpark(first.SpotID, "CAR1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.RefundTicket(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.RefundTicket(first.ID); !errors.Is(err, usecases.ErrTicketRefunded) {
+		t.Errorf("refund twice: %v, want ErrTicketRefunded", err)
+	}
+	u.Park(constants.Automobile, "CAR1")
+
+	reopened := openSQLiteLot(t, path)
+	for _, vt := range constants.Types() {
+		if got, want := reopened.AvailableSpot(vt), u.AvailableSpot(vt); got != want {
+			t.Errorf("AvailableSpot(%s) = %d, want %d", vt, got, want)
+		}
+	}
+
+	history, err := reopened.VehicleHistory("CAR1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := receipt.Ticket
+	want.Refunded = true
+	if len(history) != 2 || !reflect.DeepEqual(history[0], want) || !history[1].Active() {
+		t.Errorf("CAR1 history = %+v\nwant first %+v", history, want)
+	}
+
+	next, err := reopened.Park(constants.Automobile, "CAR3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.ID != "T00000005" {
+		t.Errorf("next ticket = %s, want T00000005", next.ID)
+	}
+	if _, err := reopened.UnparkTicket("T00000002"); err != nil {
+		t.Errorf("unpark ticket issued before reopening: %v", err)
+	}
+}
+
+func TestSQLiteRepository_RenderASCII(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lot.db")
+	u := openSQLiteLot(t, path)
+	if _, err := u.Park(constants.Automobile, "CAR1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, lot := range map[string]usecases.ParkinglotUsecase{"Live": u, "Reopened": openSQLiteLot(t, path)} {
+		var b strings.Builder
+		if err := layout.RenderFloorASCII(&b, lot.Snapshot(), 0); err != nil {
+			t.Fatal(err)
+		}
+		if want := "[Floor 0]\nB a A\n"; b.String() != want {
+			t.Errorf("%s: floor 0 = %q, want %q", name, b.String(), want)
+		}
+	}
+}
+
+func TestSQLiteRepository_Constraints(t *testing.T) {
+	repo, err := OpenSQLite(filepath.Join(t.TempDir(), "lot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	if err := repo.InitLayout(sqliteFloors); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.InitLayout(sqliteFloors); err == nil {
+		t.Error("expected error storing a second layout")
+	}
+
+	entry := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	car := domain.Ticket{ID: "T00000001", VehicleNumber: "CAR1", VehicleType: constants.Automobile, SpotID: "0-0-1", EntryTime: entry}
+	if err := repo.SaveParked(car); err != nil {
+		t.Fatal(err)
+	}
+
+	sameSpot := car
+	sameSpot.ID, sameSpot.VehicleNumber = "T00000002", "CAR2"
+	if err := repo.SaveParked(sameSpot); err == nil {
+		t.Error("expected error opening a second ticket on a spot")
+	}
+	if _, err := repo.Ticket(sameSpot.ID); !errors.Is(err, usecases.ErrTicketNotFound) {
+		t.Errorf("failed park left a ticket behind: %v", err)
+	}
+
+	closed := car
+	closed.ExitTime = entry.Add(time.Hour)
+	if err := repo.SaveUnparked(closed); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveUnparked(closed); !errors.Is(err, usecases.ErrTicketClosed) {
+		t.Errorf("close twice: %v, want ErrTicketClosed", err)
+	}
+	closed.ID = "T00000009"
+	if err := repo.SaveUnparked(closed); !errors.Is(err, usecases.ErrTicketNotFound) {
+		t.Errorf("close unknown: %v, want ErrTicketNotFound", err)
+	}
+}
+
+func TestSQLiteRepository_InvalidLayout(t *testing.T) {
+	repo, err := OpenSQLite(filepath.Join(t.TempDir(), "lot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	var layoutErr *domain.LayoutError
+	if err := repo.InitLayout([][][]string{{{"Q-1"}}}); !errors.As(err, &layoutErr) {
+		t.Errorf("err = %v, want *domain.LayoutError", err)
+	}
+}
+
+func TestSQLiteRepository_PriceKeepsOffset(t *testing.T) {
+	engine := &pricing.Engine{
+		Currency: "IDR",
+		Default: &pricing.Tariff{
+			HourlyRate: 3000,
+			Overnight:  &pricing.Overnight{Start: "22:00", End: "06:00", HourlyRate: 1000},
+		},
+	}
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	clock := time.Date(2024, 5, 1, 23, 0, 0, 0, jakarta)
+	opts := []usecases.Option{usecases.WithClock(func() time.Time { return clock }), usecases.WithPricer(engine)}
+
+	lots := map[string]usecases.ParkinglotUsecase{
+		"Memory": usecases.NewParkingLotUsecaseFromFloors(sqliteFloors, opts...),
+		"SQLite": openSQLiteLot(t, filepath.Join(t.TempDir(), "lot.db"), opts...),
+	}
+	for _, u := range lots {
+		if _, err := u.Park(constants.Automobile, "CAR1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock = clock.Add(2 * time.Hour)
+	for name, u := range lots {
+		location, err := u.SearchVehicle("CAR1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		receipt, err := u.UnparkTicket(location.Ticket.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if receipt.Fee.Amount != 2000 {
+			t.Errorf("%s: fee for 23:00-01:00 overnight = %d, want 2000", name, receipt.Fee.Amount)
+		}
+	}
+}
+
+// flatPricer charges 500 for every stay.
+type flatPricer struct{}
+
+func (flatPricer) Price(ticket domain.Ticket) (domain.Fee, error) {
+	return domain.Fee{Amount: 500, Currency: "USD"}, nil
+}
+
+func TestSQLiteRepository_Deactivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lot.db")
+	u := openSQLiteLot(t, path)
+	if _, err := u.Park(constants.Automobile, "CAR1"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := u.Deactivate("0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Spots) == 0 {
+		t.Fatal("nothing deactivated")
+	}
+
+	reopened := openSQLiteLot(t, path)
+	for _, vt := range constants.Types() {
+		if got, want := reopened.AvailableSpot(vt), u.AvailableSpot(vt); got != want {
+			t.Errorf("AvailableSpot(%s) = %d, want %d", vt, got, want)
+		}
+	}
+	if _, err := reopened.Unpark(result.Occupied[0], "CAR1"); err != nil {
+		t.Errorf("unpark from closed spot: %v", err)
+	}
+}
+
+func TestSQLiteRepository_MultiSpot(t *testing.T) {
+	const bus constants.VehicleType = "BUS"
+	if _, known := constants.Lookup(bus); !known {
+		if err := constants.Register(constants.TypeSpec{Type: bus, Name: "bus", Spots: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	compat := usecases.WithCompatibility(constants.Compatibility{bus: {constants.Automobile}})
+	path := filepath.Join(t.TempDir(), "lot.db")
+	u := openSQLiteLot(t, path, compat)
+	if _, err := u.Park(bus, "BUS1"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openSQLiteLot(t, path, compat)
+	location, err := reopened.SearchVehicle("BUS1")
+	if err != nil || location.SpotRange != "0-0-1..2" {
+		t.Errorf("SearchVehicle(BUS1) = %+v, %v", location, err)
+	}
+	if got, want := reopened.AvailableSpot(constants.Automobile), u.AvailableSpot(constants.Automobile); got != want {
+		t.Errorf("AvailableSpot(A) = %d, want %d", got, want)
+	}
+	if _, err := reopened.Unpark("0-0-2", "BUS1"); err != nil {
+		t.Errorf("unpark: %v", err)
+	}
+}
+
+func TestSQLiteRepository_Reservations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lot.db")
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	withClock := usecases.WithClock(func() time.Time { return clock })
+	u := openSQLiteLot(t, path, withClock)
+
+	held, err := u.Reserve(constants.Automobile, "CAR1", clock, clock.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	booked, err := u.Reserve(constants.Automobile, "CAR2", clock.Add(2*time.Hour), clock.Add(3*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openSQLiteLot(t, path, withClock)
+	for _, want := range []domain.Reservation{held, booked} {
+		if got, err := reopened.Reservation(want.ID); err != nil || got != want {
+			t.Errorf("Reservation(%s) = %+v, %v\nwant %+v", want.ID, got, err, want)
+		}
+	}
+	if got, want := reopened.AvailableSpot(constants.Automobile), u.AvailableSpot(constants.Automobile); got != want {
+		t.Errorf("AvailableSpot(A) = %d, want %d", got, want)
+	}
+	ticket, err := reopened.Park(constants.Automobile, "CAR1")
+	if err != nil || ticket.SpotID != held.SpotID || ticket.ReservationID != held.ID {
+		t.Fatalf("Park(CAR1) = %+v, %v", ticket, err)
+	}
+	if err := reopened.CancelReservation(booked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	last := openSQLiteLot(t, path, withClock)
+	for _, id := range []string{held.ID, booked.ID} {
+		if _, err := last.Reservation(id); !errors.Is(err, usecases.ErrReservationNotFound) {
+			t.Errorf("closed reservation %s open again: %v", id, err)
+		}
+	}
+	if history, err := last.VehicleHistory("CAR1"); err != nil || history[0].ReservationID != held.ID {
+		t.Errorf("CAR1 history = %+v, %v", history, err)
+	}
+	if next, err := last.Reserve(constants.Automobile, "CAR3", clock, clock.Add(time.Hour)); err != nil || next.ID != "R00000003" {
+		t.Errorf("next reservation = %+v, %v", next, err)
+	}
+}
+
+func TestSQLiteRepository_WaitlistOffer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lot.db")
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	withClock := usecases.WithClock(func() time.Time { return clock })
+	u := openSQLiteLot(t, path, withClock, usecases.WithWaitlist(usecases.WaitlistFIFO, time.Minute))
+
+	offers := make(chan domain.Reservation, 1)
+	if _, err := u.JoinWaitlist(constants.Automobile, "CAR1", 0, func(r domain.Reservation) { offers <- r }); err != nil {
+		t.Fatal(err)
+	}
+	offer := <-offers
+
+	// The claim window ends with the offer rather than after the longer grace period.
+	reopened := openSQLiteLot(t, path, withClock)
+	if got, err := reopened.Reservation(offer.ID); err != nil || got != offer || !got.Waitlisted {
+		t.Errorf("Reservation(%s) = %+v, %v\nwant %+v", offer.ID, got, err, offer)
+	}
+	clock = clock.Add(time.Minute)
+	if _, err := reopened.Reservation(offer.ID); !errors.Is(err, usecases.ErrReservationNotFound) {
+		t.Errorf("offer open after its claim window: %v", err)
+	}
+}
+
+func TestOpenSQLite_Migrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lot.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+CREATE TABLE tickets (
+	seq            INTEGER PRIMARY KEY,
+	id             TEXT    NOT NULL UNIQUE,
+	vehicle_number TEXT    NOT NULL,
+	vehicle_type   TEXT    NOT NULL,
+	spot_id        TEXT    NOT NULL,
+	entry_time     TEXT    NOT NULL,
+	exit_time      TEXT,
+	fee_amount     INTEGER NOT NULL DEFAULT 0,
+	fee_currency   TEXT    NOT NULL DEFAULT '',
+	payment_id     TEXT    NOT NULL DEFAULT '',
+	refunded       INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO tickets (seq, id, vehicle_number, vehicle_type, spot_id, entry_time) VALUES (1, 'T00000001', 'CAR1', 'A', '0-0-1', '2024-05-01T08:00:00Z');
+CREATE TABLE reservations (
+	seq            INTEGER PRIMARY KEY,
+	id             TEXT    NOT NULL UNIQUE,
+	vehicle_number TEXT    NOT NULL,
+	vehicle_type   TEXT    NOT NULL,
+	spot_type      TEXT    NOT NULL,
+	spot_count     INTEGER NOT NULL DEFAULT 0,
+	start_time     TEXT    NOT NULL,
+	end_time       TEXT    NOT NULL,
+	status         TEXT    NOT NULL,
+	spot_id        TEXT    NOT NULL DEFAULT ''
+);
+INSERT INTO reservations (seq, id, vehicle_number, vehicle_type, spot_type, start_time, end_time, status) VALUES (1, 'R00000001', 'CAR2', 'A', 'A', '2024-05-01T09:00:00Z', '2024-05-01T10:00:00Z', 'booked');`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	ticket, err := repo.OpenTicket("CAR1")
+	if err != nil || ticket.SpotType != "" || ticket.SpotCount != 0 || ticket.ParkedSpotType() != constants.Automobile {
+		t.Errorf("OpenTicket = %+v, %v", ticket, err)
+	}
+	if reservations, err := repo.OpenReservations(); err != nil || len(reservations) != 1 || reservations[0].Waitlisted {
+		t.Errorf("OpenReservations = %+v, %v", reservations, err)
+	}
+}
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+	"submit_do_it/domain"
+)
+
+// ActivationResult lists the spots a Deactivate or Reactivate call changed. Spots that
+// were already in the requested state, pillars and empty cells are left out.
+type ActivationResult struct {
+	Spots []string
+	// Occupied lists changed spots that still hold a vehicle. A deactivated one keeps it
+	// and takes no new vehicle after it leaves.
+	Occupied []string
+}
+
+// Deactivate closes spots to new vehicles, for cleaning, repairs or events. selector is a
+// floor ("1"), a row ("1-2") or a spot ID or label. Parked vehicles stay where they are
+// and can leave as usual.
+func (pu *parkinglotUsecaseImpl) Deactivate(selector string) (ActivationResult, error) {
+	return pu.setActive(selector, false)
+}
+
+// Reactivate opens spots closed by Deactivate, or inactive in the layout, again.
+func (pu *parkinglotUsecaseImpl) Reactivate(selector string) (ActivationResult, error) {
+	return pu.setActive(selector, true)
+}
+
+func (pu *parkinglotUsecaseImpl) setActive(selector string, active bool) (ActivationResult, error) {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
+
+	op, eventType := "deactivate", domain.EventDeactivated
+	if active {
+		op, eventType = "reactivate", domain.EventReactivated
+	}
+	request := domain.Ticket{SpotID: selector}
+
+	spots, err := pu.spotsIn(selector)
+	if err != nil {
+		return ActivationResult{}, opError(op, request, err)
+	}
+
+	var result ActivationResult
+	var changed []*domain.Spot
+	for _, spot := range spots {
+		if spot.SpotType == "" || spot.Active == active {
+			continue
+		}
+		changed = append(changed, spot)
+		result.Spots = append(result.Spots, spot.ID())
+		if spot.Occupied {
+			result.Occupied = append(result.Occupied, spot.ID())
+		}
+	}
+	if len(changed) == 0 {
+		return result, nil
+	}
+
+	if err := pu.recordEvent(domain.Event{Type: eventType, Spots: result.Spots}); err != nil {
+		return ActivationResult{}, opError(op, request, err)
+	}
+	if err := pu.repo.SaveActive(result.Spots, active); err != nil {
+		return ActivationResult{}, opError(op, request, pu.abort(fmt.Errorf("save spots: %w", err)))
+	}
+
+	for _, spot := range changed {
+		spot.Mutx.Lock()
+		spot.Active = active
+		if active {
+			pu.activeSpots[spot.SpotType][spot.Floor]++
+		} else {
+			pu.activeSpots[spot.SpotType][spot.Floor]--
+		}
+		if !spot.Occupied && spot.HeldFor == "" {
+			if active {
+				pu.pl.AvailableSpots[spot.SpotType].Add(spot)
+			} else {
+				pu.pl.AvailableSpots[spot.SpotType].Remove(spot)
+			}
+		}
+		spot.Mutx.Unlock()
+	}
+	if active {
+		pu.offerFreed()
+	}
+	return result, nil
+}
+
+// spotsIn resolves a selector to spots. Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) spotsIn(selector string) ([]*domain.Spot, error) {
+	if strings.Count(selector, "-") == 2 {
+		spot, err := pu.spotAt(selector)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.Spot{spot}, nil
+	}
+
+	r, err := domain.ParseSpotRange(selector)
+	if err != nil {
+		return nil, ErrInvalidSpotID
+	}
+	spots, err := pu.pl.SpotsIn(r)
+	if err != nil {
+		return nil, ErrSpotNotFound
+	}
+	return spots, nil
+}
@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+)
+
+type eventSlice []domain.Event
+
+func (s *eventSlice) Record(e domain.Event) error {
+	*s = append(*s, e)
+	return nil
+}
+
+func TestParkinglotUsecaseImpl_Deactivate(t *testing.T) {
+	u := NewParkingLotUsecaseFromFloors([][][]string{
+		{{"A-1", "A-1"}, {"A-1", "#"}},
+		{{"A-1", "B-1"}},
+	})
+	ticket, err := u.Park(constants.Automobile, "CAR1")
+	if err != nil || ticket.SpotID != "0-0-0" {
+		t.Fatalf("Park = %+v, %v", ticket, err)
+	}
+
+	result, err := u.Deactivate("0")
+	if err != nil {
+		t.Fatalf("Deactivate: %v", err)
+	}
+	if want := []string{"0-0-0", "0-0-1", "0-1-0"}; !slices.Equal(result.Spots, want) {
+		t.Errorf("Spots = %v, want %v", result.Spots, want)
+	}
+	if want := []string{"0-0-0"}; !slices.Equal(result.Occupied, want) {
+		t.Errorf("Occupied = %v, want %v", result.Occupied, want)
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 1 {
+		t.Errorf("AvailableSpot(A) = %d, want 1", got)
+	}
+
+	again, err := u.Deactivate("0-1")
+	if err != nil || len(again.Spots) != 0 {
+		t.Errorf("deactivating closed spots again = %+v, %v", again, err)
+	}
+
+	next, err := u.Park(constants.Automobile, "CAR2")
+	if err != nil || next.SpotID != "1-0-0" {
+		t.Errorf("Park after closing floor 0 = %+v, %v", next, err)
+	}
+	if _, err := u.UnparkTicket(ticket.ID); err != nil {
+		t.Fatalf("vehicle on a closed spot cannot leave: %v", err)
+	}
+	if _, err := u.Park(constants.Automobile, "CAR3"); !errors.Is(err, ErrLotFull) {
+		t.Errorf("spot freed on a closed floor took a vehicle: %v", err)
+	}
+
+	if _, err := u.Reactivate("0-0-1"); err != nil {
+		t.Fatalf("Reactivate: %v", err)
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 1 {
+		t.Errorf("AvailableSpot(A) after reopening one spot = %d, want 1", got)
+	}
+	if result, err := u.Reactivate("0"); err != nil || len(result.Spots) != 2 {
+		t.Errorf("Reactivate(0) = %+v, %v", result, err)
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 3 {
+		t.Errorf("AvailableSpot(A) after reopening floor = %d, want 3", got)
+	}
+}
+
+func TestParkinglotUsecaseImpl_Deactivate_Errors(t *testing.T) {
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1", "A-1"}}})
+
+	tests := []struct {
+		selector string
+		want     error
+	}{
+		{"", ErrInvalidSpotID},
+		{"x", ErrInvalidSpotID},
+		{"0-0-0-0", ErrInvalidSpotID},
+		{"1", ErrSpotNotFound},
+		{"0-1", ErrSpotNotFound},
+		{"0-0-2", ErrSpotNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			if _, err := u.Deactivate(tt.selector); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 2 {
+		t.Errorf("AvailableSpot(A) = %d, want 2", got)
+	}
+}
+
+func TestParkinglotUsecaseImpl_Deactivate_Restore(t *testing.T) {
+	floors := [][][]string{{{"A-1", "A-1", "A-1"}}}
+	var events eventSlice
+	u := NewParkingLotUsecaseFromFloors(floors, WithEventRecorder(&events))
+	u.Park(constants.Automobile, "CAR1")
+	if _, err := u.Deactivate("0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Reactivate("0-0-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	empty := &domain.Snapshot{Version: domain.SnapshotVersion, Floors: floors}
+	replayed, err := NewParkingLotUsecaseFromEvents(empty, events)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	restored, err := NewParkingLotUsecaseFromSnapshot(u.Snapshot())
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	for name, lot := range map[string]ParkinglotUsecase{"replayed": replayed, "restored": restored} {
+		if got := lot.AvailableSpot(constants.Automobile); got != 1 {
+			t.Errorf("%s: AvailableSpot(A) = %d, want 1", name, got)
+		}
+		if _, err := lot.Unpark("0-0-0", "CAR1"); err != nil {
+			t.Errorf("%s: unpark from closed spot: %v", name, err)
+		}
+		if got := lot.AvailableSpot(constants.Automobile); got != 1 {
+			t.Errorf("%s: closed spot reopened after unpark", name)
+		}
+	}
+}
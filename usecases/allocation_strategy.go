@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"submit_do_it/domain"
+)
+
+// FloorCandidate summarises the free spots of the requested type on one floor.
+type FloorCandidate struct {
+	Floor    int
+	Free     int
+	Capacity int          // active spots of the type on the floor, free or not
+	Best     *domain.Spot // most preferred free spot on the floor according to Less
+}
+
+// AllocationStrategy decides which free spot Park hands out. Allocation happens in two
+// steps: Less orders the free spots within a floor, then PickFloor chooses among the
+// floors that still have a free spot. Candidates are passed in ascending floor order and
+// PickFloor returns an index into them. Implementations must be deterministic.
+type AllocationStrategy interface {
+	Less(a, b *domain.Spot) bool
+	PickFloor(candidates []FloorCandidate) int
+}
+
+func lessRowCol(a, b *domain.Spot) bool {
+	if a.Row != b.Row {
+		return a.Row < b.Row
+	}
+	return a.Col < b.Col
+}
+
+// LowestFirstStrategy hands out the spot with the lowest floor, then row, then column.
+type LowestFirstStrategy struct{}
+
+func (LowestFirstStrategy) Less(a, b *domain.Spot) bool {
+	return lessRowCol(a, b)
+}
+
+func (LowestFirstStrategy) PickFloor(candidates []FloorCandidate) int {
+	return 0
+}
+
+// FillFloorStrategy keeps filling the fullest floor that still has room before opening
+// up emptier ones, so whole floors can be closed off when the lot is quiet. Fullness is
+// the share of the floor's spots in use, so floors of different sizes compare fairly.
+type FillFloorStrategy struct{}
+
+func (FillFloorStrategy) Less(a, b *domain.Spot) bool {
+	return lessRowCol(a, b)
+}
+
+func (FillFloorStrategy) PickFloor(candidates []FloorCandidate) int {
+	best := 0
+	for i, c := range candidates {
+		// c.Free/c.Capacity < b.Free/b.Capacity without dividing.
+		if b := candidates[best]; c.Free*b.Capacity < b.Free*c.Capacity {
+			best = i
+		}
+	}
+	return best
+}
+
+// SpreadFloorsStrategy parks on the floor with the most free spots to balance traffic.
+type SpreadFloorsStrategy struct{}
+
+func (SpreadFloorsStrategy) Less(a, b *domain.Spot) bool {
+	return lessRowCol(a, b)
+}
+
+func (SpreadFloorsStrategy) PickFloor(candidates []FloorCandidate) int {
+	best := 0
+	for i, c := range candidates {
+		if c.Free > candidates[best].Free {
+			best = i
+		}
+	}
+	return best
+}
+
+// NearestEntranceStrategy hands out the spot closest to an entrance, measured as the
+// walking distance in rows and columns plus FloorCost for every floor changed.
+type NearestEntranceStrategy struct {
+	Floor     int
+	Row       int
+	Col       int
+	FloorCost int
+}
+
+func (s NearestEntranceStrategy) distance(spot *domain.Spot) int {
+	return abs(spot.Row-s.Row) + abs(spot.Col-s.Col)
+}
+
+func (s NearestEntranceStrategy) Less(a, b *domain.Spot) bool {
+	if da, db := s.distance(a), s.distance(b); da != db {
+		return da < db
+	}
+	return lessRowCol(a, b)
+}
+
+func (s NearestEntranceStrategy) PickFloor(candidates []FloorCandidate) int {
+	best, bestCost := 0, 0
+	for i, c := range candidates {
+		cost := abs(c.Floor-s.Floor)*s.FloorCost + s.distance(c.Best)
+		if i == 0 || cost < bestCost {
+			best, bestCost = i, cost
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package usecases
+
+import (
+	"reflect"
+	"testing"
+
+	"submit_do_it/constants"
+)
+
+func parkAll(t *testing.T, u ParkinglotUsecase, n int) []string {
+	t.Helper()
+	var spotIDs []string
+	for i := 0; i < n; i++ {
+		ticket, err := u.Park(constants.Automobile, "CAR"+string(rune('A'+i)))
+		if err != nil {
+			t.Fatalf("Park #%d failed: %v", i, err)
+		}
+		spotIDs = append(spotIDs, ticket.SpotID)
+	}
+	return spotIDs
+}
+
+func TestAllocationStrategies(t *testing.T) {
+	floorTemplates := [][][]string{
+		{
+			{"A-1", "A-1", "A-1"},
+			{"A-1", "A-1", "A-1"},
+		},
+		{
+			{"A-1", "A-1", "A-1"},
+			{"A-1", "A-1", "A-1"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		strategy AllocationStrategy
+		want     []string
+	}{
+		{"Default", nil, []string{"0-0-0", "0-0-1", "0-0-2", "0-1-0"}},
+		{"LowestFirst", LowestFirstStrategy{}, []string{"0-0-0", "0-0-1", "0-0-2", "0-1-0"}},
+		{"FillFloor", FillFloorStrategy{}, []string{"0-0-0", "0-0-1", "0-0-2", "0-1-0"}},
+		{"SpreadFloors", SpreadFloorsStrategy{}, []string{"0-0-0", "1-0-0", "0-0-1", "1-0-1"}},
+		{"NearestEntrance", NearestEntranceStrategy{Floor: 1, Row: 1, Col: 2, FloorCost: 10}, []string{"1-1-2", "1-0-2", "1-1-1", "1-0-1"}},
+		{"NearestEntranceCheapStairs", NearestEntranceStrategy{Floor: 1, Row: 1, Col: 2, FloorCost: 1}, []string{"1-1-2", "0-1-2", "1-0-2", "1-1-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.strategy != nil {
+				opts = append(opts, WithAllocationStrategy(tt.strategy))
+			}
+			u := NewParkingLotUsecaseFromFloors(floorTemplates, opts...)
+
+			if got := parkAll(t, u, len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocated %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillFloorStrategy_PrefersFullestFloor(t *testing.T) {
+	u := NewParkingLotUsecaseFromFloors([][][]string{
+		{{"A-1", "A-1", "A-1"}},
+		{{"A-1", "A-1", "A-1"}},
+	}, WithAllocationStrategy(FillFloorStrategy{}))
+
+	parkAll(t, u, 4)
+	if _, err := u.Unpark("0-0-0", "CARA"); err != nil {
+		t.Fatalf("Unpark failed: %v", err)
+	}
+	if _, err := u.Unpark("0-0-1", "CARB"); err != nil {
+		t.Fatalf("Unpark failed: %v", err)
+	}
+
+	// Floor 0 now has 2 free spots and floor 1 has 2; tie goes to the lower floor.
+	// After one more car floor 0 is the fuller floor and keeps being filled.
+	ticket, err := u.Park(constants.Automobile, "CARX")
+	if err != nil || ticket.SpotID != "0-0-0" {
+		t.Fatalf("Park = %q, %v; want 0-0-0", ticket.SpotID, err)
+	}
+	ticket, err = u.Park(constants.Automobile, "CARY")
+	if err != nil || ticket.SpotID != "0-0-1" {
+		t.Errorf("Park = %q, %v; want 0-0-1", ticket.SpotID, err)
+	}
+}
+
+func TestFillFloorStrategy_UnequalFloors(t *testing.T) {
+	u := NewParkingLotUsecaseFromFloors([][][]string{
+		{{"A-1", "A-1", "A-1", "A-1"}},
+		{{"A-1", "A-1", "A-1", "A-1", "A-1"}, {"A-1", "A-1", "A-1", "A-1", "A-1"}},
+	}, WithAllocationStrategy(FillFloorStrategy{}))
+
+	spots := parkAll(t, u, 10)
+	for i, number := range []string{"CARA", "CARB", "CARC"} {
+		if _, err := u.Unpark(spots[i], number); err != nil {
+			t.Fatalf("Unpark failed: %v", err)
+		}
+	}
+
+	// Floor 0 has 3 of 4 spots free and floor 1 has 4 of 10, so floor 1 is the fuller one
+	// even though it has more free spots.
+	ticket, err := u.Park(constants.Automobile, "CARX")
+	if err != nil || ticket.SpotID != "1-1-1" {
+		t.Errorf("Park = %q, %v; want 1-1-1", ticket.SpotID, err)
+	}
+}
+
+func TestAllocationStrategy_Deterministic(t *testing.T) {
+	layoutTemplate := [][]string{
+		{"A-1", "A-1", "A-1", "A-1"},
+		{"A-1", "A-1", "A-1", "A-1"},
+	}
+	first := parkAll(t, NewParkingLotUsecase(3, 2, 4, layoutTemplate), 20)
+	for i := 0; i < 5; i++ {
+		if got := parkAll(t, NewParkingLotUsecase(3, 2, 4, layoutTemplate), 20); !reflect.DeepEqual(got, first) {
+			t.Fatalf("allocation not deterministic: %v vs %v", got, first)
+		}
+	}
+}
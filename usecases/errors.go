@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"errors"
+	"strings"
+	"submit_do_it/constants"
+)
+
+var (
+	ErrAlreadyParked      = errors.New("vehicle already parked")
+	ErrLotFull            = errors.New("no available parking spot for vehicle type")
+	ErrUnknownVehicleType = errors.New("unknown vehicle type")
+	ErrInvalidSpotID      = errors.New("invalid spot ID")
+	ErrSpotNotFound       = errors.New("spot not found")
+	ErrVehicleMismatch    = errors.New("vehicle not found at specified spot")
+	ErrSpotNotOccupied    = errors.New("spot not occupied by this vehicle")
+	ErrVehicleNotFound    = errors.New("vehicle not found")
+	ErrTicketNotFound     = errors.New("ticket not found")
+	ErrTicketClosed       = errors.New("ticket already closed")
+	ErrTicketNotPaid      = errors.New("ticket has no captured payment")
+	ErrTicketRefunded     = errors.New("ticket already refunded")
+	ErrPaymentDeclined    = errors.New("payment declined")
+	ErrPaymentsDisabled   = errors.New("payments not enabled")
+
+	ErrInvalidReservation  = errors.New("invalid reservation window")
+	ErrAlreadyReserved     = errors.New("vehicle already has a reservation in that window")
+	ErrReservationNotFound = errors.New("reservation not found")
+
+	ErrWaitlistDisabled = errors.New("waitlist not enabled")
+	ErrAlreadyWaiting   = errors.New("vehicle already on the waitlist")
+)
+
+// ParkingError is returned by ParkinglotUsecase methods. It wraps one of the errors above
+// with whatever the operation knew about the vehicle, spot and ticket involved, so callers
+// can match it with errors.Is and read the details with errors.As.
+type ParkingError struct {
+	Op            string // "park", "unpark", "unpark ticket", "refund", "search", "history", "reserve", "reservation", "cancel reservation", "join waitlist" or "leave waitlist"
+	VehicleNumber string
+	VehicleType   constants.VehicleType
+	SpotID        string
+	TicketID      string
+	ReservationID string
+	Err           error
+}
+
+func (e *ParkingError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	for _, field := range []struct{ name, value string }{
+		{"vehicle", e.VehicleNumber},
+		{"type", string(e.VehicleType)},
+		{"spot", e.SpotID},
+		{"ticket", e.TicketID},
+		{"reservation", e.ReservationID},
+	} {
+		if field.value != "" {
+			b.WriteString(" " + field.name + " " + field.value)
+		}
+	}
+	b.WriteString(": " + e.Err.Error())
+	return b.String()
+}
+
+func (e *ParkingError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKind groups errors by how a caller should react to them. The HTTP, gRPC and CLI
+// front ends each translate kinds, not individual errors, into their own status codes so
+// the same failure is reported the same way everywhere.
+type ErrorKind int
+
+const (
+	KindInternal           ErrorKind = iota // anything not listed below
+	KindInvalid                             // malformed input: ErrInvalidSpotID, ErrUnknownVehicleType, ErrInvalidReservation
+	KindNotFound                            // ErrVehicleNotFound, ErrSpotNotFound, ErrVehicleMismatch, ErrTicketNotFound, ErrReservationNotFound
+	KindAlreadyExists                       // ErrAlreadyParked, ErrAlreadyReserved, ErrAlreadyWaiting
+	KindExhausted                           // ErrLotFull
+	KindFailedPrecondition                  // ErrSpotNotOccupied, ErrTicketClosed, ErrTicketNotPaid, ErrTicketRefunded, ErrWaitlistDisabled, ErrPaymentsDisabled
+	KindPaymentRequired                     // ErrPaymentDeclined
+)
+
+var errorKinds = []struct {
+	err  error
+	kind ErrorKind
+	code string
+}{
+	{ErrInvalidSpotID, KindInvalid, "invalid_spot_id"},
+	{ErrUnknownVehicleType, KindInvalid, "unknown_vehicle_type"},
+	{ErrInvalidReservation, KindInvalid, "invalid_reservation"},
+	{ErrVehicleNotFound, KindNotFound, "vehicle_not_found"},
+	{ErrSpotNotFound, KindNotFound, "spot_not_found"},
+	{ErrVehicleMismatch, KindNotFound, "vehicle_mismatch"},
+	{ErrTicketNotFound, KindNotFound, "ticket_not_found"},
+	{ErrReservationNotFound, KindNotFound, "reservation_not_found"},
+	{ErrAlreadyParked, KindAlreadyExists, "already_parked"},
+	{ErrAlreadyReserved, KindAlreadyExists, "already_reserved"},
+	{ErrAlreadyWaiting, KindAlreadyExists, "already_waiting"},
+	{ErrLotFull, KindExhausted, "lot_full"},
+	{ErrSpotNotOccupied, KindFailedPrecondition, "spot_not_occupied"},
+	{ErrTicketClosed, KindFailedPrecondition, "ticket_closed"},
+	{ErrTicketNotPaid, KindFailedPrecondition, "ticket_not_paid"},
+	{ErrTicketRefunded, KindFailedPrecondition, "ticket_refunded"},
+	{ErrWaitlistDisabled, KindFailedPrecondition, "waitlist_disabled"},
+	{ErrPaymentsDisabled, KindFailedPrecondition, "payments_disabled"},
+	{ErrPaymentDeclined, KindPaymentRequired, "payment_declined"},
+}
+
+// KindOf classifies err by the first error above that it matches.
+func KindOf(err error) ErrorKind {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return KindInternal
+}
+
+// CodeOf returns a stable snake_case name for the error above that err matches, such as
+// "lot_full", or "internal". Front ends expose it so clients need not match messages.
+func CodeOf(err error) string {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.code
+		}
+	}
+	return "internal"
+}
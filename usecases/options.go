@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"time"
+)
+
+// Pricer computes the fee for a closed ticket. pricing.Engine implements it.
+type Pricer interface {
+	Price(ticket domain.Ticket) (domain.Fee, error)
+}
+
+// PaymentGateway collects fees before a vehicle may leave. Authorize reserves the fee and
+// returns an authorization ID that Capture settles, Void releases if it was never
+// captured and Refund pays back. Declines must wrap ErrPaymentDeclined. Refunding a
+// payment already refunded must succeed without paying out again, so a refund whose
+// outcome was not recorded can be retried.
+type PaymentGateway interface {
+	Authorize(reference string, fee domain.Fee) (string, error)
+	Capture(authorizationID string) error
+	Void(authorizationID string) error
+	Refund(authorizationID string) error
+}
+
+// EventRecorder persists every state change before it is applied to the lot. Record must
+// not return until the event is as durable as the recorder promises; if it fails the
+// change is abandoned. If the repository then rejects the change, an EventAborted event
+// cancels it. store.EventLog implements it.
+type EventRecorder interface {
+	Record(event domain.Event) error
+}
+
+type Option func(*parkinglotUsecaseImpl)
+
+func WithAllocationStrategy(strategy AllocationStrategy) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		pu.strategy = strategy
+	}
+}
+
+// WithClock replaces time.Now as the source of ticket entry and exit times.
+func WithClock(now func() time.Time) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		pu.now = now
+	}
+}
+
+// WithPricer charges every unpark through pricer. Without it stays are free.
+func WithPricer(pricer Pricer) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		pu.pricer = pricer
+	}
+}
+
+// WithPaymentGateway requires every non-zero fee to be captured before a spot is released.
+func WithPaymentGateway(gateway PaymentGateway) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		pu.payments = gateway
+	}
+}
+
+// WithEventRecorder records every state change through recorder, so the lot can
+// be rebuilt with NewParkingLotUsecaseFromEvents after a crash.
+func WithEventRecorder(recorder EventRecorder) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		pu.events = recorder
+	}
+}
+
+// WithCompatibility lets vehicles fall back to other spot types, in the order compat
+// lists them, when spots of their own type are full. compat should pass Validate.
+func WithCompatibility(compat constants.Compatibility) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		pu.compat = compat
+	}
+}
+
+// WithReservationGrace sets how long after its window opens a reservation holds its spot
+// for a vehicle that has not arrived. The default is DefaultReservationGrace.
+func WithReservationGrace(grace time.Duration) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		pu.grace = grace
+	}
+}
+
+// WithWaitlist lets vehicles turned away with ErrLotFull join a waitlist, served in order.
+// A freed spot is held for the vehicle offered it for claim.
+func WithWaitlist(order WaitlistOrder, claim time.Duration) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		pu.waitlist = newWaitlist(order, claim)
+	}
+}
+
+// WithSpotLabels lets Unpark and the spot selectors accept spot labels such as "L2-B-14" as
+// well as canonical IDs, and SpotLabel write them. Tickets keep canonical IDs. Labels that
+// fail Validate are reported by the constructors that return an error and ignored by the
+// others.
+func WithSpotLabels(labels domain.SpotLabels) Option {
+	return func(pu *parkinglotUsecaseImpl) {
+		if err := labels.Validate(); err != nil {
+			pu.optionErr = errors.Join(pu.optionErr, fmt.Errorf("spot labels: %w", err))
+			return
+		}
+		pu.labels = &labels
+	}
+}
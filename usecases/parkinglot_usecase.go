@@ -3,155 +3,720 @@ package usecases
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"submit_do_it/constants"
 	"submit_do_it/domain"
+	"time"
 )
 
 type parkinglotUsecaseImpl struct {
-	pl *domain.ParkingLot
+	pl       *domain.ParkingLot
+	strategy AllocationStrategy
+	now      func() time.Time
+	pricer   Pricer
+	payments PaymentGateway
+	events   EventRecorder
+	repo     ParkingRepository
+	labels   *domain.SpotLabels
+	compat   constants.Compatibility
+	grace    time.Duration
+	waitlist *waitlist
+
+	activeSpots map[constants.VehicleType][]int // active spots per floor, by spot type
+	optionErr   error                           // invalid options, reported by the constructors that can fail
+	offers      []func()                        // waitlist notifications due once pl.Mutx is released
 }
 
 type ParkinglotUsecase interface {
-	Park(vehicleType constants.VehicleType, vehicleNumber string) (string, error)
-	Unpark(spotId, vehicleNumber string) error
+	Park(vehicleType constants.VehicleType, vehicleNumber string) (domain.Ticket, error)
+	Unpark(spotId, vehicleNumber string) (domain.Receipt, error)
+	UnparkTicket(ticketID string) (domain.Receipt, error)
+	RefundTicket(ticketID string) error
 	AvailableSpot(vehicleType constants.VehicleType) int
-	SearchVehicle(vehicleNumber string) (string, error)
+	SearchVehicle(vehicleNumber string) (domain.VehicleLocation, error)
+	VehicleHistory(vehicleNumber string) ([]domain.Ticket, error)
+	Deactivate(selector string) (ActivationResult, error)
+	Reactivate(selector string) (ActivationResult, error)
+	Reserve(vehicleType constants.VehicleType, vehicleNumber string, start, end time.Time) (domain.Reservation, error)
+	Reservation(id string) (domain.Reservation, error)
+	CancelReservation(id string) error
+	JoinWaitlist(vehicleType constants.VehicleType, vehicleNumber string, priority int, notify func(domain.Reservation)) (int, error)
+	LeaveWaitlist(vehicleNumber string) error
+	WaitlistEntry(vehicleNumber string) (int, domain.Reservation, error)
+	Snapshot() *domain.Snapshot
+	SpotLabel(spotID string) string
+}
+
+// NewParkingLotUsecase stamps layoutTemplate onto every floor. It never fails: codes that
+// cannot be parsed and cells missing from the template become inactive spots.
+// Use NewParkingLotUsecaseE to have those problems reported.
+func NewParkingLotUsecase(floors, rows, columns int, layoutTemplate [][]string, opts ...Option) ParkinglotUsecase {
+	layout, _ := domain.ParseLayout(repeatTemplate(floors, rows, columns, layoutTemplate))
+	pu, _ := newParkinglotUsecase(newParkingLot(layout, rows, columns), opts)
+	return pu
+}
+
+// NewParkingLotUsecaseE is like NewParkingLotUsecase but rejects templates that are not
+// exactly rows x columns and reports every invalid code.
+func NewParkingLotUsecaseE(floors, rows, columns int, layoutTemplate [][]string, opts ...Option) (ParkinglotUsecase, error) {
+	if err := domain.ValidateTemplate(floors, rows, columns, layoutTemplate); err != nil {
+		return nil, err
+	}
+
+	layout, err := domain.ParseLayout(repeatTemplate(floors, rows, columns, layoutTemplate))
+	if err != nil {
+		return nil, err
+	}
+	return newParkinglotUsecase(newParkingLot(layout, rows, columns), opts)
+}
+
+// NewParkingLotUsecaseFromFloors builds a lot with a distinct template per floor.
+// Floors may differ in size; Rows and Columns hold the largest dimensions seen.
+// Like NewParkingLotUsecase, invalid codes become inactive spots.
+func NewParkingLotUsecaseFromFloors(floorTemplates [][][]string, opts ...Option) ParkinglotUsecase {
+	layout, _ := domain.ParseLayout(floorTemplates)
+	pu, _ := newParkinglotUsecase(newParkingLot(layout, 0, 0), opts)
+	return pu
+}
+
+// NewParkingLotUsecaseFromFloorsE is like NewParkingLotUsecaseFromFloors but reports
+// invalid codes and ragged rows instead of ignoring them.
+func NewParkingLotUsecaseFromFloorsE(floorTemplates [][][]string, opts ...Option) (ParkinglotUsecase, error) {
+	layout, err := domain.ParseLayout(floorTemplates)
+	if err != nil {
+		return nil, err
+	}
+	return newParkinglotUsecase(newParkingLot(layout, 0, 0), opts)
+}
+
+// NewParkingLotUsecaseFromSnapshot restores a lot saved with Snapshot. Open
+// tickets park their vehicles again and AvailableSpots is rebuilt from what is left.
+// Snapshots that do not hold together, such as two open tickets on one spot, are rejected.
+func NewParkingLotUsecaseFromSnapshot(snap *domain.Snapshot, opts ...Option) (ParkinglotUsecase, error) {
+	return NewParkingLotUsecaseFromEvents(snap, nil, opts...)
+}
+
+// NewParkingLotUsecaseFromEvents restores snap and replays the events recorded since.
+// Events already covered by the snapshot are skipped.
+func NewParkingLotUsecaseFromEvents(snap *domain.Snapshot, events []domain.Event, opts ...Option) (ParkinglotUsecase, error) {
+	layout, err := domain.ParseLayout(snap.Floors)
+	if err != nil {
+		return nil, err
+	}
+
+	lot := newParkingLot(layout, 0, 0)
+	if err := snap.RestoreTickets(lot); err != nil {
+		return nil, err
+	}
+	if err := lot.ApplyEvents(events); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	return newParkinglotUsecase(lot, opts)
+}
+
+// NewParkingLotUsecaseFromRepository runs a lot on top of repo, loading its layout and
+// parked vehicles. Snapshots and the event log only cover lots kept in memory; a
+// repository is durable on its own.
+func NewParkingLotUsecaseFromRepository(repo ParkingRepository, opts ...Option) (ParkinglotUsecase, error) {
+	floors, err := repo.Layout()
+	if err != nil {
+		return nil, fmt.Errorf("load layout: %w", err)
+	}
+	layout, err := domain.ParseLayout(floors)
+	if err != nil {
+		return nil, err
+	}
+	lot := newParkingLot(layout, 0, 0)
+
+	if lot.TicketSeq, err = repo.TicketSeq(); err != nil {
+		return nil, fmt.Errorf("load ticket sequence: %w", err)
+	}
+	open, err := repo.OpenTickets()
+	if err != nil {
+		return nil, fmt.Errorf("load open tickets: %w", err)
+	}
+	for _, ticket := range open {
+		spots, err := lot.TicketSpots(&ticket)
+		if err != nil {
+			return nil, fmt.Errorf("ticket %s: %w", ticket.ID, err)
+		}
+		for _, spot := range spots {
+			if spot.Occupied {
+				return nil, fmt.Errorf("ticket %s: spot %s already occupied by %s", ticket.ID, spot.ID(), spot.VehicleNumber)
+			}
+			spot.Occupied = true
+			spot.VehicleNumber = ticket.VehicleNumber
+		}
+	}
+	if lot.ReservationSeq, err = repo.ReservationSeq(); err != nil {
+		return nil, fmt.Errorf("load reservation sequence: %w", err)
+	}
+	reservations, err := repo.OpenReservations()
+	if err != nil {
+		return nil, fmt.Errorf("load open reservations: %w", err)
+	}
+	for _, r := range reservations {
+		if err := lot.RestoreReservation(r, lot.ReservationSeq); err != nil {
+			return nil, fmt.Errorf("reservation %s: %w", r.ID, err)
+		}
+	}
+
+	pu, err := newParkinglotUsecase(lot, opts)
+	if err != nil {
+		return nil, err
+	}
+	pu.repo = repo
+	return pu, nil
+}
+
+// newParkinglotUsecase applies opts to a usecase over lot. It returns the usecase even
+// when an option was invalid, for the constructors that never fail.
+func newParkinglotUsecase(lot *domain.ParkingLot, opts []Option) (*parkinglotUsecaseImpl, error) {
+	pu := &parkinglotUsecaseImpl{
+		pl:       lot,
+		strategy: LowestFirstStrategy{},
+		now:      time.Now,
+		repo:     memoryRepository{pl: lot},
+		grace:    DefaultReservationGrace,
+	}
+	for _, opt := range opts {
+		opt(pu)
+	}
+	pu.indexFreeSpots()
+	return pu, pu.optionErr
+}
+
+// repeatTemplate copies template onto each floor, padding missing cells with empty codes.
+func repeatTemplate(floors, rows, columns int, template [][]string) [][][]string {
+	floorTemplates := make([][][]string, max(floors, 0))
+	for f := range floorTemplates {
+		floorTemplates[f] = make([][]string, max(rows, 0))
+		for r := range floorTemplates[f] {
+			floorTemplates[f][r] = make([]string, max(columns, 0))
+			if r < len(template) {
+				copy(floorTemplates[f][r], template[r])
+			}
+		}
+	}
+	return floorTemplates
 }
 
-func NewParkingLotUsecase(floors, rows, columns int, layoutTemplate [][]string) ParkinglotUsecase {
+func newParkingLot(layout [][][]*domain.Spot, rows, columns int) *domain.ParkingLot {
 	lot := &domain.ParkingLot{
-		Floors:         floors,
+		Floors:         len(layout),
 		Rows:           rows,
 		Columns:        columns,
-		Layout:         make([][][]*domain.Spot, floors),
+		Layout:         layout,
 		VehicleMap:     make(map[string]string),
-		LastSpotMap:    make(map[string]string),
-		AvailableSpots: make(map[constants.VehicleType]map[string]*domain.Spot),
-	}
-
-	for _, vt := range []constants.VehicleType{
-		constants.Bicycle,
-		constants.Motorcycle,
-		constants.Automobile,
-	} {
-		lot.AvailableSpots[vt] = make(map[string]*domain.Spot)
-	}
-
-	for f := 0; f < floors; f++ {
-		lot.Layout[f] = make([][]*domain.Spot, rows)
-		for r := 0; r < rows; r++ {
-			lot.Layout[f][r] = make([]*domain.Spot, columns)
-			for c := 0; c < columns; c++ {
-				code := layoutTemplate[r][c]
-				parts := strings.Split(code, "-")
-				spotType := parts[0]
-				active := parts[1] == "1"
-
-				var vt constants.VehicleType
-				switch spotType {
-				case "B":
-					vt = constants.Bicycle
-				case "M":
-					vt = constants.Motorcycle
-				case "A":
-					vt = constants.Automobile
-				default:
-					active = false
-				}
+		History:        make(map[string][]*domain.Ticket),
+		Tickets:        make(map[string]*domain.Ticket),
+		Reservations:   make(map[string]*domain.Reservation),
+		AvailableSpots: make(map[constants.VehicleType]*domain.FreeSpots),
+	}
 
-				spot := &domain.Spot{
-					Floor:    f,
-					Row:      r,
-					Col:      c,
-					SpotType: vt,
-					Active:   active,
-				}
+	for _, floor := range layout {
+		lot.Rows = max(lot.Rows, len(floor))
+		for _, row := range floor {
+			lot.Columns = max(lot.Columns, len(row))
+		}
+	}
+	return lot
+}
 
-				lot.Layout[f][r][c] = spot
+// indexFreeSpots builds AvailableSpots ordered by the allocation strategy, and counts the
+// active spots of every floor.
+func (pu *parkinglotUsecaseImpl) indexFreeSpots() {
+	pu.activeSpots = make(map[constants.VehicleType][]int)
+	for _, vt := range constants.Types() {
+		pu.pl.AvailableSpots[vt] = domain.NewFreeSpots(len(pu.pl.Layout), pu.strategy.Less)
+		pu.activeSpots[vt] = make([]int, len(pu.pl.Layout))
+	}
 
-				if active {
-					spotID := spot.ID()
-					lot.AvailableSpots[vt][spotID] = spot
+	for _, floor := range pu.pl.Layout {
+		for _, row := range floor {
+			for _, spot := range row {
+				if spot.Active && spot.SpotType != "" {
+					pu.activeSpots[spot.SpotType][spot.Floor]++
+				}
+				if spot.Active && !spot.Occupied && spot.HeldFor == "" {
+					pu.pl.AvailableSpots[spot.SpotType].Add(spot)
 				}
 			}
 		}
 	}
-	return &parkinglotUsecaseImpl{
-		pl: lot,
-	}
 }
 
-func (pu *parkinglotUsecaseImpl) Park(vehicleType constants.VehicleType, vehicleNumber string) (string, error) {
+func (pu *parkinglotUsecaseImpl) Park(vehicleType constants.VehicleType, vehicleNumber string) (domain.Ticket, error) {
 	pu.pl.Mutx.Lock()
-	defer pu.pl.Mutx.Unlock()
+	defer pu.unlock()
 
-	if _, exists := pu.pl.VehicleMap[vehicleNumber]; exists {
-		return "", errors.New("vehicle already parked")
+	request := domain.Ticket{VehicleNumber: vehicleNumber, VehicleType: vehicleType}
+	if _, known := pu.pl.AvailableSpots[vehicleType]; !known {
+		return domain.Ticket{}, opError("park", request, ErrUnknownVehicleType)
+	}
+	if err := pu.syncReservations(); err != nil {
+		return domain.Ticket{}, opError("park", request, err)
+	}
+	if open, err := pu.repo.OpenTicket(vehicleNumber); err == nil {
+		return domain.Ticket{}, opError("park", open, ErrAlreadyParked)
+	} else if !errors.Is(err, ErrVehicleNotFound) {
+		return domain.Ticket{}, opError("park", request, err)
 	}
 
-	for spotID, spot := range pu.pl.AvailableSpots[vehicleType] {
-		spot.Mutx.Lock()
-		if spot.Occupied {
-			spot.Mutx.Unlock()
-			continue
+	count := 1
+	if spec, ok := constants.Lookup(vehicleType); ok {
+		count = spec.SpotCount()
+	}
+	var spots []*domain.Spot
+	reservation := pu.heldReservation(vehicleType, vehicleNumber)
+	if reservation != nil {
+		held, err := pu.pl.HeldSpots(reservation)
+		if err != nil {
+			return domain.Ticket{}, opError("park", request, fmt.Errorf("reservation %s: %w", reservation.ID, err))
+		}
+		spots, count = held, len(held)
+	}
+	for _, spotType := range pu.compat.SpotTypes(vehicleType) {
+		if spots != nil {
+			break
 		}
+		spots = pu.selectSpots(spotType, count)
+	}
+	if spots == nil {
+		return domain.Ticket{}, opError("park", request, ErrLotFull)
+	}
+
+	ticket := domain.Ticket{
+		ID:            domain.TicketID(pu.pl.TicketSeq + 1),
+		VehicleNumber: vehicleNumber,
+		VehicleType:   vehicleType,
+		SpotID:        spots[0].ID(),
+		SpotType:      spots[0].SpotType,
+		EntryTime:     pu.now(),
+	}
+	if count > 1 {
+		ticket.SpotCount = count
+	}
+	if reservation != nil {
+		ticket.ReservationID = reservation.ID
+	}
+	if err := pu.record(domain.EventParked, ticket); err != nil {
+		return domain.Ticket{}, opError("park", ticket, err)
+	}
+	if err := pu.repo.SaveParked(ticket); err != nil {
+		return domain.Ticket{}, opError("park", ticket, pu.abort(fmt.Errorf("save ticket: %w", err)))
+	}
+
+	for _, spot := range spots {
+		spot.Mutx.Lock()
 		spot.Occupied = true
 		spot.VehicleNumber = vehicleNumber
 		spot.Mutx.Unlock()
+		pu.pl.AvailableSpots[spot.SpotType].Remove(spot)
+	}
+	if reservation != nil {
+		pu.pl.ReleaseSpots(reservation)
+		delete(pu.pl.Reservations, reservation.ID)
+	}
+	if pu.waitlist != nil {
+		pu.waitlist.remove(vehicleNumber)
+	}
+	pu.pl.TicketSeq++
+	return ticket, nil
+}
+
+// selectSpots asks the allocation strategy for n adjacent free spots of spotType in one
+// row and returns them in column order, or nil if there are none. Callers must hold
+// pl.Mutx.
+func (pu *parkinglotUsecaseImpl) selectSpots(spotType constants.VehicleType, n int) []*domain.Spot {
+	free := pu.pl.AvailableSpots[spotType]
+	if free.Len() < n {
+		return nil
+	}
+
+	best := make([]*domain.Spot, free.Floors())
+	if n == 1 {
+		for f := range best {
+			best[f] = free.Peek(f)
+		}
+	} else {
+		// Vehicles taking several spots are rare, so their runs are found by scanning the
+		// free spots rather than kept in an index.
+		for _, spot := range free.Spots() {
+			if f := spot.Floor; pu.freeRun(free, spot, n) && (best[f] == nil || pu.strategy.Less(spot, best[f])) {
+				best[f] = spot
+			}
+		}
+	}
+
+	candidates := make([]FloorCandidate, 0, len(best))
+	for f, spot := range best {
+		if spot != nil {
+			candidates = append(candidates, FloorCandidate{
+				Floor:    f,
+				Free:     free.FloorLen(f),
+				Capacity: pu.activeSpots[spotType][f],
+				Best:     spot,
+			})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	start := candidates[pu.strategy.PickFloor(candidates)].Best
+	spots, _ := pu.pl.SpotsOf(domain.SpotSpan{Start: start.SpotID(), Len: n})
+	return spots
+}
+
+// freeRun reports whether start and the n-1 spots to its right are all free in free.
+func (pu *parkinglotUsecaseImpl) freeRun(free *domain.FreeSpots, start *domain.Spot, n int) bool {
+	spots, err := pu.pl.SpotsOf(domain.SpotSpan{Start: start.SpotID(), Len: n})
+	if err != nil {
+		return false
+	}
+	for _, spot := range spots {
+		if !free.Contains(spot) {
+			return false
+		}
+	}
+	return true
+}
+
+func (pu *parkinglotUsecaseImpl) Unpark(spotID, vehicleNumber string) (domain.Receipt, error) {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
 
-		pu.pl.VehicleMap[vehicleNumber] = spotID
-		pu.pl.LastSpotMap[vehicleNumber] = spotID
-		delete(pu.pl.AvailableSpots[vehicleType], spotID)
-		return spotID, nil
+	request := domain.Ticket{VehicleNumber: vehicleNumber, SpotID: spotID}
+	spot, err := pu.spotAt(spotID)
+	if err != nil {
+		return domain.Receipt{}, opError("unpark", request, err)
+	}
+	request.SpotID = spot.ID()
+
+	ticket, err := pu.repo.OpenTicket(vehicleNumber)
+	if errors.Is(err, ErrVehicleNotFound) || err == nil && !holds(ticket, spot) {
+		return domain.Receipt{}, opError("unpark", request, ErrVehicleMismatch)
+	}
+	if err != nil {
+		return domain.Receipt{}, opError("unpark", request, err)
 	}
 
-	return "", errors.New("no available parking spot for vehicle type")
+	receipt, err := pu.closeTicket(ticket)
+	if err != nil {
+		return domain.Receipt{}, opError("unpark", ticket, err)
+	}
+	pu.offerFreed()
+	return receipt, nil
 }
 
-func (pu *parkinglotUsecaseImpl) Unpark(spotID, vehicleNumber string) error {
+// UnparkTicket closes an open ticket and frees its spot.
+func (pu *parkinglotUsecaseImpl) UnparkTicket(ticketID string) (domain.Receipt, error) {
 	pu.pl.Mutx.Lock()
-	defer pu.pl.Mutx.Unlock()
+	defer pu.unlock()
+
+	ticket, err := pu.repo.Ticket(ticketID)
+	if err != nil {
+		return domain.Receipt{}, opError("unpark ticket", domain.Ticket{ID: ticketID}, err)
+	}
+	if !ticket.Active() {
+		return domain.Receipt{}, opError("unpark ticket", ticket, ErrTicketClosed)
+	}
 
-	if pu.pl.VehicleMap[vehicleNumber] != spotID {
-		return errors.New("vehicle not found at specified spot")
+	receipt, err := pu.closeTicket(ticket)
+	if err != nil {
+		return domain.Receipt{}, opError("unpark ticket", ticket, err)
 	}
+	pu.offerFreed()
+	return receipt, nil
+}
+
+// offerFreed hands spots just freed to waiting reservations and the waitlist. The spots
+// are already free, so a failure is left for the next operation that syncs to retry.
+// Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) offerFreed() {
+	_ = pu.syncReservations()
+}
+
+// holds reports whether spot is one of the spots held by ticket, so a vehicle taking
+// several spots can be unparked from any of them.
+func holds(ticket domain.Ticket, spot *domain.Spot) bool {
+	span, err := ticket.Span()
+	return err == nil && span.Contains(spot.SpotID())
+}
 
-	_ = strings.Split(spotID, "-")
-	var f, r, c int
-	fmt.Sscanf(spotID, "%d-%d-%d", &f, &r, &c)
+// spotAt resolves a canonical spot ID, or a label if the lot has labels, against the
+// layout. Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) spotAt(spotID string) (*domain.Spot, error) {
+	parse := domain.ParseSpotID
+	if pu.labels != nil {
+		parse = pu.labels.Parse
+	}
+	id, err := parse(spotID)
+	if err != nil {
+		return nil, ErrInvalidSpotID
+	}
+	spot, err := pu.pl.Spot(id)
+	if err != nil {
+		return nil, ErrSpotNotFound
+	}
+	return spot, nil
+}
 
-	spot := pu.pl.Layout[f][r][c]
-	spot.Mutx.Lock()
-	defer spot.Mutx.Unlock()
+// closeTicket checks that the ticket's vehicle still occupies its spots and checks it out.
+// Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) closeTicket(ticket domain.Ticket) (domain.Receipt, error) {
+	spots, err := pu.pl.TicketSpots(&ticket)
+	if err != nil {
+		return domain.Receipt{}, err
+	}
+	for _, spot := range spots {
+		spot.Mutx.Lock()
+		defer spot.Mutx.Unlock()
+	}
 
-	if !spot.Occupied || spot.VehicleNumber != vehicleNumber {
-		return errors.New("spot not occupied by this vehicle")
+	for _, spot := range spots {
+		if !spot.Occupied || spot.VehicleNumber != ticket.VehicleNumber {
+			return domain.Receipt{}, ErrSpotNotOccupied
+		}
 	}
+	return pu.checkout(spots, ticket)
+}
 
-	spot.Occupied = false
-	spot.VehicleNumber = ""
-	delete(pu.pl.VehicleMap, vehicleNumber)
-	pu.pl.AvailableSpots[spot.SpotType][spotID] = spot
+// checkout prices the stay, collects payment and records the exit; only if all succeed is
+// the ticket closed and the spot freed. An authorization that cannot be captured is
+// voided, and a payment whose exit cannot be recorded is refunded. Callers must hold pl.Mutx and the Mutx of every spot.
+func (pu *parkinglotUsecaseImpl) checkout(spots []*domain.Spot, ticket domain.Ticket) (domain.Receipt, error) {
+	closed := ticket
+	closed.ExitTime = pu.now()
 
+	if pu.pricer != nil {
+		fee, err := pu.pricer.Price(closed)
+		if err != nil {
+			return domain.Receipt{}, fmt.Errorf("price: %w", err)
+		}
+		closed.Fee = fee
+	}
+
+	if pu.payments != nil && closed.Fee.Amount > 0 {
+		authorizationID, err := pu.payments.Authorize(ticket.ID, closed.Fee)
+		if err != nil {
+			return domain.Receipt{}, fmt.Errorf("payment: %w", err)
+		}
+		if err := pu.payments.Capture(authorizationID); err != nil {
+			if voidErr := pu.payments.Void(authorizationID); voidErr != nil {
+				return domain.Receipt{}, fmt.Errorf("payment: %w; void of %s failed: %v", err, authorizationID, voidErr)
+			}
+			return domain.Receipt{}, fmt.Errorf("payment: %w", err)
+		}
+		closed.PaymentID = authorizationID
+	}
+
+	if err := pu.saveUnparked(closed); err != nil {
+		if closed.PaymentID != "" {
+			if refundErr := pu.payments.Refund(closed.PaymentID); refundErr != nil {
+				return domain.Receipt{}, fmt.Errorf("%w; refund of %s failed: %v", err, closed.PaymentID, refundErr)
+			}
+		}
+		return domain.Receipt{}, err
+	}
+
+	for _, spot := range spots {
+		spot.Occupied = false
+		spot.VehicleNumber = ""
+		if spot.Active {
+			pu.pl.AvailableSpots[spot.SpotType].Add(spot)
+		}
+	}
+
+	return domain.Receipt{Ticket: closed, Fee: closed.Fee}, nil
+}
+
+func (pu *parkinglotUsecaseImpl) saveUnparked(closed domain.Ticket) error {
+	if err := pu.record(domain.EventUnparked, closed); err != nil {
+		return err
+	}
+	if err := pu.repo.SaveUnparked(closed); err != nil {
+		return pu.abort(fmt.Errorf("save ticket: %w", err))
+	}
 	return nil
 }
 
+// RefundTicket pays back the fee captured for a closed ticket. The gateway refunds before
+// the ticket is marked, so if marking fails the refund is retried, which the gateway
+// treats as already done.
+func (pu *parkinglotUsecaseImpl) RefundTicket(ticketID string) error {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
+
+	ticket, err := pu.repo.Ticket(ticketID)
+	if err != nil {
+		return opError("refund", domain.Ticket{ID: ticketID}, err)
+	}
+	if ticket.PaymentID == "" {
+		return opError("refund", ticket, ErrTicketNotPaid)
+	}
+	if ticket.Refunded {
+		return opError("refund", ticket, ErrTicketRefunded)
+	}
+	if pu.payments == nil {
+		return opError("refund", ticket, ErrPaymentsDisabled)
+	}
+
+	if err := pu.payments.Refund(ticket.PaymentID); err != nil {
+		return opError("refund", ticket, err)
+	}
+	ticket.Refunded = true
+	if err := pu.record(domain.EventRefunded, ticket); err != nil {
+		return opError("refund", ticket, err)
+	}
+	if err := pu.repo.SaveRefunded(ticket.ID); err != nil {
+		return opError("refund", ticket, pu.abort(fmt.Errorf("save ticket: %w", err)))
+	}
+	return nil
+}
+
+// opError wraps err with what is known about the ticket involved in op.
+func opError(op string, ticket domain.Ticket, err error) error {
+	return &ParkingError{
+		Op:            op,
+		VehicleNumber: ticket.VehicleNumber,
+		VehicleType:   ticket.VehicleType,
+		SpotID:        ticket.SpotID,
+		TicketID:      ticket.ID,
+		Err:           err,
+	}
+}
+
+// AvailableSpot counts the free spots of a type. Spots held for reservations are not free,
+// and neither are spots a reservation whose window has opened is about to hold. It only
+// reads the lot: holds start and end on the next operation that changes it.
 func (pu *parkinglotUsecaseImpl) AvailableSpot(vehicleType constants.VehicleType) int {
 	pu.pl.Mutx.RLock()
 	defer pu.pl.Mutx.RUnlock()
-	return len(pu.pl.AvailableSpots[vehicleType])
+
+	free := pu.pl.AvailableSpots[vehicleType].Len()
+	now := pu.now()
+	for _, r := range pu.pl.Reservations {
+		if r.SpotType != vehicleType {
+			continue
+		}
+		switch {
+		case !now.Before(pu.holdDeadline(r)):
+			if r.Status == domain.ReservationHeld && !pu.waitlist.wants(vehicleType, pu.compat) {
+				free += max(r.SpotCount, 1)
+			}
+		case r.Status == domain.ReservationBooked && !now.Before(r.Start):
+			free -= max(r.SpotCount, 1)
+		}
+	}
+	return max(free, 0)
+}
+
+func (pu *parkinglotUsecaseImpl) SearchVehicle(vehicleNumber string) (domain.VehicleLocation, error) {
+	history, err := pu.VehicleHistory(vehicleNumber)
+	if err != nil {
+		var perr *ParkingError
+		if errors.As(err, &perr) {
+			perr.Op = "search"
+		}
+		return domain.VehicleLocation{}, err
+	}
+
+	ticket := history[len(history)-1]
+	return domain.VehicleLocation{
+		SpotID:    ticket.SpotID,
+		SpotRange: ticket.SpotRange(),
+		Parked:    ticket.Active(),
+		Ticket:    ticket,
+	}, nil
 }
 
-func (pu *parkinglotUsecaseImpl) SearchVehicle(vehicleNumber string) (string, error) {
+// VehicleHistory returns every ticket issued to a vehicle, oldest first.
+func (pu *parkinglotUsecaseImpl) VehicleHistory(vehicleNumber string) ([]domain.Ticket, error) {
 	pu.pl.Mutx.RLock()
 	defer pu.pl.Mutx.RUnlock()
 
-	if spot, ok := pu.pl.VehicleMap[vehicleNumber]; ok {
-		return spot, nil
+	history, err := pu.repo.History(vehicleNumber)
+	if err != nil {
+		return nil, opError("history", domain.Ticket{VehicleNumber: vehicleNumber}, err)
 	}
-	if last, ok := pu.pl.LastSpotMap[vehicleNumber]; ok {
-		return last, nil
+	if len(history) == 0 {
+		return nil, opError("history", domain.Ticket{VehicleNumber: vehicleNumber}, ErrVehicleNotFound)
 	}
-	return "", errors.New("vehicle not found")
+	return history, nil
+}
+
+// unlock releases pl.Mutx, then delivers the waitlist offers made while it was held, so
+// they reach their vehicles before the operation that made them returns.
+func (pu *parkinglotUsecaseImpl) unlock() {
+	offers := pu.offers
+	pu.offers = nil
+	pu.pl.Mutx.Unlock()
+	for _, offer := range offers {
+		offer()
+	}
+}
+
+// record hands an event to the recorder, if any. Callers must hold pl.Mutx for writing and
+// apply the change only if record succeeds.
+func (pu *parkinglotUsecaseImpl) record(eventType domain.EventType, ticket domain.Ticket) error {
+	return pu.recordEvent(domain.Event{Type: eventType, Ticket: ticket})
+}
+
+// recordEvent numbers and timestamps event and records it. Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) recordEvent(event domain.Event) error {
+	if pu.events == nil {
+		return nil
+	}
+
+	event.Seq = pu.pl.EventSeq + 1
+	event.Time = pu.now()
+	if err := pu.events.Record(event); err != nil {
+		return fmt.Errorf("record %s event: %w", event.Type, err)
+	}
+	pu.pl.EventSeq = event.Seq
+	return nil
+}
+
+// abort records that the repository rejected the change of the last recorded event, so
+// replay skips it, and returns err. Callers must hold pl.Mutx and call it right after the
+// failed save.
+func (pu *parkinglotUsecaseImpl) abort(err error) error {
+	if pu.events == nil {
+		return err
+	}
+	if abortErr := pu.recordEvent(domain.Event{Type: domain.EventAborted, Aborted: pu.pl.EventSeq}); abortErr != nil {
+		return fmt.Errorf("%w; %v", err, abortErr)
+	}
+	return err
+}
+
+// SpotLabel writes a spot ID or span, such as "0-1-3" or "0-1-3..5", the way signs in the
+// lot show it. Without WithSpotLabels, or for anything it cannot parse, spotID is
+// returned unchanged.
+func (pu *parkinglotUsecaseImpl) SpotLabel(spotID string) string {
+	if pu.labels == nil {
+		return spotID
+	}
+	first, last, isSpan := strings.Cut(spotID, "..")
+	id, err := domain.ParseSpotID(first)
+	if err != nil {
+		return spotID
+	}
+	span := domain.SpotSpan{Start: id, Len: 1}
+	if isSpan {
+		end, err := strconv.Atoi(last)
+		if err != nil || end < id.Col {
+			return spotID
+		}
+		span.Len = end - id.Col + 1
+	}
+	return pu.labels.FormatSpan(span)
+}
+
+// Snapshot returns a copy of the lot taken now, for callers that need to look at its
+// layout or tickets without reaching into the live state.
+func (pu *parkinglotUsecaseImpl) Snapshot() *domain.Snapshot {
+	return pu.pl.Snapshot(pu.now())
 }
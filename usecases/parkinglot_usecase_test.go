@@ -1,10 +1,14 @@
 package usecases
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
 	"submit_do_it/constants"
+	"submit_do_it/domain"
 )
 
 func TestNewParkingLotUsecase_BasicLayout(t *testing.T) {
@@ -47,14 +51,14 @@ func TestNewParkingLotUsecase_BasicLayout(t *testing.T) {
 		}
 	}
 
-	// Check AvailableSpots map
+	// Check AvailableSpots index
 	expectedAvailable := map[constants.VehicleType]int{
 		constants.Bicycle:    1,
 		constants.Motorcycle: 2,
 		constants.Automobile: 1,
 	}
 	for vt, want := range expectedAvailable {
-		got := len(pl.AvailableSpots[vt])
+		got := pl.AvailableSpots[vt].Len()
 		if got != want {
 			t.Errorf("AvailableSpots[%v]: want %d, got %d", vt, want, got)
 		}
@@ -115,7 +119,7 @@ func TestNewParkingLotUsecase_EmptyLayout(t *testing.T) {
 		t.Errorf("expected 0 rows, got %d", len(pl.Layout[0]))
 	}
 	for vt := range pl.AvailableSpots {
-		if len(pl.AvailableSpots[vt]) != 0 {
+		if pl.AvailableSpots[vt].Len() != 0 {
 			t.Errorf("expected 0 available spots for %v", vt)
 		}
 	}
@@ -129,8 +133,8 @@ func TestNewParkingLotUsecase_MapsInitialized(t *testing.T) {
 	impl := u.(*parkinglotUsecaseImpl)
 	pl := impl.pl
 
-	if pl.VehicleMap == nil || pl.LastSpotMap == nil {
-		t.Errorf("VehicleMap or LastSpotMap not initialized")
+	if pl.VehicleMap == nil || pl.History == nil {
+		t.Errorf("VehicleMap or History not initialized")
 	}
 	for _, vt := range []constants.VehicleType{constants.Bicycle, constants.Motorcycle, constants.Automobile} {
 		if pl.AvailableSpots[vt] == nil {
@@ -148,10 +152,9 @@ func TestNewParkingLotUsecase_SpotIDs(t *testing.T) {
 	pl := impl.pl
 
 	for vt, spots := range pl.AvailableSpots {
-		for spotID, spot := range spots {
-			expectedID := spot.ID()
-			if spotID != expectedID {
-				t.Errorf("spotID mismatch: got %s, want %s", spotID, expectedID)
+		for _, spot := range spots.Spots() {
+			if pl.Layout[spot.Floor][spot.Row][spot.Col] != spot {
+				t.Errorf("spot %s is not at its layout position", spot.ID())
 			}
 			if spot.SpotType != vt {
 				t.Errorf("spot type mismatch: got %v, want %v", spot.SpotType, vt)
@@ -160,6 +163,138 @@ func TestNewParkingLotUsecase_SpotIDs(t *testing.T) {
 	}
 }
 
+func TestNewParkingLotUsecaseFromFloors_DistinctFloors(t *testing.T) {
+	floorTemplates := [][][]string{
+		{
+			{"B-1", "B-1", "B-1"},
+		},
+		{
+			{"A-1", "A-1"},
+			{"A-1", "A-0"},
+		},
+	}
+	u := NewParkingLotUsecaseFromFloors(floorTemplates)
+	impl := u.(*parkinglotUsecaseImpl)
+	pl := impl.pl
+
+	if pl.Floors != 2 || pl.Rows != 2 || pl.Columns != 3 {
+		t.Errorf("unexpected lot dimensions: floors=%d rows=%d columns=%d", pl.Floors, pl.Rows, pl.Columns)
+	}
+	if len(pl.Layout[0]) != 1 || len(pl.Layout[0][0]) != 3 {
+		t.Errorf("floor 0 should be 1x3, got %dx%d", len(pl.Layout[0]), len(pl.Layout[0][0]))
+	}
+	if len(pl.Layout[1]) != 2 || len(pl.Layout[1][0]) != 2 {
+		t.Errorf("floor 1 should be 2x2, got %dx%d", len(pl.Layout[1]), len(pl.Layout[1][0]))
+	}
+
+	expectedAvailable := map[constants.VehicleType]int{
+		constants.Bicycle:    3,
+		constants.Motorcycle: 0,
+		constants.Automobile: 3,
+	}
+	for vt, want := range expectedAvailable {
+		if got := pl.AvailableSpots[vt].Len(); got != want {
+			t.Errorf("AvailableSpots[%v]: want %d, got %d", vt, want, got)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, floor := range pl.Layout {
+		for _, row := range floor {
+			for _, spot := range row {
+				if seen[spot.ID()] {
+					t.Errorf("duplicate spot ID %s", spot.ID())
+				}
+				seen[spot.ID()] = true
+			}
+		}
+	}
+
+	if _, err := impl.Park(constants.Bicycle, "BIKE123"); err != nil {
+		t.Errorf("expected bicycle to park on ground floor, got %v", err)
+	}
+	ticket, err := impl.Park(constants.Automobile, "CAR123")
+	if err != nil {
+		t.Fatalf("expected car to park on upper deck, got %v", err)
+	}
+	spotID := ticket.SpotID
+	if spotID[0] != '1' {
+		t.Errorf("expected car on floor 1, got %s", spotID)
+	}
+}
+
+func TestNewParkingLotUsecase_MalformedTemplateDoesNotPanic(t *testing.T) {
+	layoutTemplate := [][]string{
+		{"B", "M-1"},
+	}
+	u := NewParkingLotUsecase(1, 2, 2, layoutTemplate)
+	impl := u.(*parkinglotUsecaseImpl)
+	pl := impl.pl
+
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 2; c++ {
+			if pl.Layout[0][r][c] == nil {
+				t.Fatalf("spot at 0-%d-%d not built", r, c)
+			}
+		}
+	}
+	if got := impl.AvailableSpot(constants.Motorcycle); got != 1 {
+		t.Errorf("expected 1 motorcycle spot, got %d", got)
+	}
+	if got := impl.AvailableSpot(constants.Bicycle); got != 0 {
+		t.Errorf("expected malformed bicycle code to be inactive, got %d", got)
+	}
+}
+
+func TestNewParkingLotUsecaseE(t *testing.T) {
+	tests := []struct {
+		name     string
+		floors   int
+		rows     int
+		columns  int
+		template [][]string
+		wantErr  bool
+	}{
+		{"Valid", 2, 1, 2, [][]string{{"B-1", "A-0"}}, false},
+		{"UnknownType", 1, 1, 2, [][]string{{"X-1", "A-1"}}, true},
+		{"MissingSeparator", 1, 1, 1, [][]string{{"B"}}, true},
+		{"TooFewRows", 1, 2, 1, [][]string{{"B-1"}}, true},
+		{"TooFewColumns", 1, 1, 2, [][]string{{"B-1"}}, true},
+		{"TooManyColumns", 1, 1, 1, [][]string{{"B-1", "B-1"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := NewParkingLotUsecaseE(tt.floors, tt.rows, tt.columns, tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewParkingLotUsecaseE error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && u != nil {
+				t.Errorf("expected nil usecase on error, got %T", u)
+			}
+			if !tt.wantErr && u == nil {
+				t.Errorf("expected usecase, got nil")
+			}
+		})
+	}
+}
+
+func TestNewParkingLotUsecaseFromFloorsE(t *testing.T) {
+	if _, err := NewParkingLotUsecaseFromFloorsE([][][]string{{{"B-1"}}, {{"A-1", "A-1"}}}); err != nil {
+		t.Errorf("expected floors of different sizes to be accepted, got %v", err)
+	}
+
+	_, err := NewParkingLotUsecaseFromFloorsE([][][]string{{{"B-1"}}, {{"A-1", "Z-1"}}})
+	var layoutErr *domain.LayoutError
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("expected *domain.LayoutError, got %v", err)
+	}
+	var cellErr *domain.CellError
+	if !errors.As(layoutErr.Errors[0], &cellErr) || cellErr.Floor != 1 || cellErr.Col != 1 {
+		t.Errorf("expected error at floor 1 col 1, got %v", layoutErr.Errors[0])
+	}
+}
+
 func TestParkinglotUsecaseImpl_Park_Success(t *testing.T) {
 	layoutTemplate := [][]string{
 		{"B-1", "M-1", "A-1"},
@@ -167,10 +302,11 @@ func TestParkinglotUsecaseImpl_Park_Success(t *testing.T) {
 	u := NewParkingLotUsecase(1, 1, 3, layoutTemplate)
 	impl := u.(*parkinglotUsecaseImpl)
 
-	spotID, err := impl.Park(constants.Bicycle, "BIKE123")
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
+	spotID := ticket.SpotID
 	if spotID == "" {
 		t.Errorf("expected a spotID, got empty string")
 	}
@@ -184,7 +320,7 @@ func TestParkinglotUsecaseImpl_Park_Success(t *testing.T) {
 		t.Errorf("spot not marked as occupied or wrong vehicle number")
 	}
 	// Check that the spot is removed from AvailableSpots
-	if _, ok := impl.pl.AvailableSpots[constants.Bicycle][spotID]; ok {
+	if impl.pl.AvailableSpots[constants.Bicycle].Contains(spot) {
 		t.Errorf("spot should be removed from AvailableSpots after parking")
 	}
 }
@@ -196,16 +332,17 @@ func TestParkinglotUsecaseImpl_Park_AlreadyParked(t *testing.T) {
 	u := NewParkingLotUsecase(1, 1, 1, layoutTemplate)
 	impl := u.(*parkinglotUsecaseImpl)
 
-	spotID, err := impl.Park(constants.Bicycle, "BIKE123")
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
 	if err != nil {
 		t.Fatalf("first park should succeed, got %v", err)
 	}
-	spotID2, err2 := impl.Park(constants.Bicycle, "BIKE123")
+	spotID := ticket.SpotID
+	ticket2, err2 := impl.Park(constants.Bicycle, "BIKE123")
 	if err2 == nil {
 		t.Errorf("expected error for already parked vehicle, got nil")
 	}
-	if spotID2 != "" {
-		t.Errorf("expected empty spotID for already parked vehicle, got %v", spotID2)
+	if ticket2.SpotID != "" {
+		t.Errorf("expected empty spotID for already parked vehicle, got %v", ticket2.SpotID)
 	}
 	// Should still be mapped to the first spot
 	if got := impl.pl.VehicleMap["BIKE123"]; got != spotID {
@@ -251,19 +388,20 @@ func TestParkinglotUsecaseImpl_Unpark_Success(t *testing.T) {
 	impl := u.(*parkinglotUsecaseImpl)
 
 	// Park a bicycle
-	spotID, err := impl.Park(constants.Bicycle, "BIKE123")
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
 	if err != nil {
 		t.Fatalf("Park failed: %v", err)
 	}
+	spotID := ticket.SpotID
 
 	// Unpark the bicycle
-	err = impl.Unpark(spotID, "BIKE123")
+	_, err = impl.Unpark(spotID, "BIKE123")
 	if err != nil {
 		t.Errorf("expected no error on unpark, got %v", err)
 	}
 
 	// Spot should be available again
-	if _, ok := impl.pl.AvailableSpots[constants.Bicycle][spotID]; !ok {
+	if !impl.pl.AvailableSpots[constants.Bicycle].Contains(impl.pl.Layout[0][0][0]) {
 		t.Errorf("spot should be available after unpark")
 	}
 	// Vehicle should be removed from VehicleMap
@@ -290,7 +428,7 @@ func TestParkinglotUsecaseImpl_Unpark_WrongSpotID(t *testing.T) {
 	}
 
 	// Try to unpark with wrong spotID
-	err = impl.Unpark("0-0-99", "BIKE123")
+	_, err = impl.Unpark("0-0-99", "BIKE123")
 	if err == nil {
 		t.Errorf("expected error for wrong spotID, got nil")
 	}
@@ -307,13 +445,14 @@ func TestParkinglotUsecaseImpl_Unpark_WrongVehicleNumber(t *testing.T) {
 	u := NewParkingLotUsecase(1, 1, 1, layoutTemplate)
 	impl := u.(*parkinglotUsecaseImpl)
 
-	spotID, err := impl.Park(constants.Bicycle, "BIKE123")
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
 	if err != nil {
 		t.Fatalf("Park failed: %v", err)
 	}
+	spotID := ticket.SpotID
 
 	// Try to unpark with wrong vehicle number
-	err = impl.Unpark(spotID, "BIKE999")
+	_, err = impl.Unpark(spotID, "BIKE999")
 	if err == nil {
 		t.Errorf("expected error for wrong vehicle number, got nil")
 	}
@@ -331,7 +470,7 @@ func TestParkinglotUsecaseImpl_Unpark_SpotNotOccupied(t *testing.T) {
 	impl := u.(*parkinglotUsecaseImpl)
 
 	// Try to unpark when nothing is parked
-	err := impl.Unpark("0-0-0", "BIKE123")
+	_, err := impl.Unpark("0-0-0", "BIKE123")
 	if err == nil {
 		t.Errorf("expected error when spot not occupied, got nil")
 	}
@@ -344,17 +483,18 @@ func TestParkinglotUsecaseImpl_Unpark_SpotOccupiedByOtherVehicle(t *testing.T) {
 	u := NewParkingLotUsecase(1, 1, 1, layoutTemplate)
 	impl := u.(*parkinglotUsecaseImpl)
 
-	spotID, err := impl.Park(constants.Bicycle, "BIKE123")
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
 	if err != nil {
 		t.Fatalf("Park failed: %v", err)
 	}
+	spotID := ticket.SpotID
 
 	// Manually change the vehicle number at the spot
 	spot := impl.pl.Layout[0][0][0]
 	spot.VehicleNumber = "BIKE999"
 
 	// Try to unpark with original vehicle number
-	err = impl.Unpark(spotID, "BIKE123")
+	_, err = impl.Unpark(spotID, "BIKE123")
 	if err == nil {
 		t.Errorf("expected error when spot occupied by another vehicle, got nil")
 	}
@@ -395,15 +535,16 @@ func TestParkinglotUsecaseImpl_AvailableSpot_AfterParkingAndUnparking(t *testing
 		t.Errorf("expected 2 available, got %d", got)
 	}
 
-	spotID, err := impl.Park(constants.Bicycle, "BIKE1")
+	ticket, err := impl.Park(constants.Bicycle, "BIKE1")
 	if err != nil {
 		t.Fatalf("Park failed: %v", err)
 	}
+	spotID := ticket.SpotID
 	if got := impl.AvailableSpot(constants.Bicycle); got != 1 {
 		t.Errorf("expected 1 available after parking, got %d", got)
 	}
 
-	err = impl.Unpark(spotID, "BIKE1")
+	_, err = impl.Unpark(spotID, "BIKE1")
 	if err != nil {
 		t.Fatalf("Unpark failed: %v", err)
 	}
@@ -430,17 +571,21 @@ func TestParkinglotUsecaseImpl_SearchVehicle_Parked(t *testing.T) {
 	u := NewParkingLotUsecase(1, 1, 2, layoutTemplate)
 	impl := u.(*parkinglotUsecaseImpl)
 
-	spotID, err := impl.Park(constants.Bicycle, "BIKE123")
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
 	if err != nil {
 		t.Fatalf("Park failed: %v", err)
 	}
+	spotID := ticket.SpotID
 
-	foundSpot, err := impl.SearchVehicle("BIKE123")
+	found, err := impl.SearchVehicle("BIKE123")
 	if err != nil {
 		t.Errorf("expected to find vehicle, got error: %v", err)
 	}
-	if foundSpot != spotID {
-		t.Errorf("expected spotID %s, got %s", spotID, foundSpot)
+	if found.SpotID != spotID {
+		t.Errorf("expected spotID %s, got %s", spotID, found.SpotID)
+	}
+	if !found.Parked {
+		t.Errorf("expected vehicle to be reported as parked")
 	}
 }
 
@@ -451,21 +596,25 @@ func TestParkinglotUsecaseImpl_SearchVehicle_LastSpot(t *testing.T) {
 	u := NewParkingLotUsecase(1, 1, 1, layoutTemplate)
 	impl := u.(*parkinglotUsecaseImpl)
 
-	spotID, err := impl.Park(constants.Bicycle, "BIKE123")
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
 	if err != nil {
 		t.Fatalf("Park failed: %v", err)
 	}
-	err = impl.Unpark(spotID, "BIKE123")
+	spotID := ticket.SpotID
+	_, err = impl.Unpark(spotID, "BIKE123")
 	if err != nil {
 		t.Fatalf("Unpark failed: %v", err)
 	}
 
-	foundSpot, err := impl.SearchVehicle("BIKE123")
+	found, err := impl.SearchVehicle("BIKE123")
 	if err != nil {
 		t.Errorf("expected to find last spot, got error: %v", err)
 	}
-	if foundSpot != spotID {
-		t.Errorf("expected last spotID %s, got %s", spotID, foundSpot)
+	if found.SpotID != spotID {
+		t.Errorf("expected last spotID %s, got %s", spotID, found.SpotID)
+	}
+	if found.Parked {
+		t.Errorf("expected vehicle to be reported as no longer parked")
 	}
 }
 
@@ -481,3 +630,511 @@ func TestParkinglotUsecaseImpl_SearchVehicle_NotFound(t *testing.T) {
 		t.Errorf("expected error for unknown vehicle, got nil")
 	}
 }
+
+func TestParkinglotUsecaseImpl_VehicleHistory(t *testing.T) {
+	layoutTemplate := [][]string{
+		{"B-1", "B-1"},
+	}
+	u := NewParkingLotUsecase(1, 1, 2, layoutTemplate)
+	impl := u.(*parkinglotUsecaseImpl)
+
+	if _, err := impl.VehicleHistory("BIKE123"); err == nil {
+		t.Errorf("expected error for unknown vehicle, got nil")
+	}
+
+	// Occupy the first spot so the second visit lands elsewhere.
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	first := ticket.SpotID
+	if _, err := impl.Park(constants.Bicycle, "BIKE456"); err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	if _, err := impl.Unpark(first, "BIKE123"); err != nil {
+		t.Fatalf("Unpark failed: %v", err)
+	}
+	if _, err := impl.Unpark("0-0-1", "BIKE456"); err != nil {
+		t.Fatalf("Unpark failed: %v", err)
+	}
+	if _, err := impl.Park(constants.Bicycle, "BIKE456"); err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	ticket, err = impl.Park(constants.Bicycle, "BIKE123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	second := ticket.SpotID
+
+	history, err := impl.VehicleHistory("BIKE123")
+	if err != nil {
+		t.Fatalf("VehicleHistory failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 tickets, got %d", len(history))
+	}
+	if history[0].SpotID != first || history[0].Active() || history[0].ExitTime.Before(history[0].EntryTime) {
+		t.Errorf("first ticket should be closed at %s: %+v", first, history[0])
+	}
+	if history[1].SpotID != second || !history[1].Active() {
+		t.Errorf("second ticket should be open at %s: %+v", second, history[1])
+	}
+
+	// History is a copy and cannot alter lot state.
+	history[1].SpotID = "tampered"
+	if found, _ := impl.SearchVehicle("BIKE123"); found.SpotID != second || !found.Parked {
+		t.Errorf("SearchVehicle = %+v, want parked at %s", found, second)
+	}
+}
+
+func TestParkinglotUsecaseImpl_UnparkTicket(t *testing.T) {
+	layoutTemplate := [][]string{
+		{"B-1", "A-1"},
+	}
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	u := NewParkingLotUsecase(1, 1, 2, layoutTemplate, WithClock(func() time.Time { return now }))
+	impl := u.(*parkinglotUsecaseImpl)
+
+	ticket, err := impl.Park(constants.Automobile, "CAR123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	if ticket.ID == "" || ticket.VehicleNumber != "CAR123" || ticket.VehicleType != constants.Automobile || ticket.SpotID != "0-0-1" {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+	if !ticket.EntryTime.Equal(now) || !ticket.Active() {
+		t.Errorf("ticket should be open since %v: %+v", now, ticket)
+	}
+
+	other, err := impl.Park(constants.Bicycle, "BIKE123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	if other.ID == ticket.ID {
+		t.Errorf("ticket IDs should be unique, both are %s", ticket.ID)
+	}
+
+	now = now.Add(2*time.Hour + 15*time.Minute)
+	receipt, err := impl.UnparkTicket(ticket.ID)
+	if err != nil {
+		t.Fatalf("UnparkTicket failed: %v", err)
+	}
+	if closed := receipt.Ticket; !closed.ExitTime.Equal(now) || closed.Duration() != 2*time.Hour+15*time.Minute {
+		t.Errorf("unexpected closed ticket: exit %v duration %v", closed.ExitTime, closed.Duration())
+	}
+	if got := impl.AvailableSpot(constants.Automobile); got != 1 {
+		t.Errorf("expected spot to be free after UnparkTicket, got %d available", got)
+	}
+	if found, _ := impl.SearchVehicle("CAR123"); found.Parked {
+		t.Errorf("vehicle should no longer be parked")
+	}
+
+	if _, err := impl.UnparkTicket(ticket.ID); err == nil {
+		t.Errorf("expected error when closing a ticket twice, got nil")
+	}
+	if _, err := impl.UnparkTicket("T-unknown"); err == nil {
+		t.Errorf("expected error for unknown ticket, got nil")
+	}
+}
+
+func TestParkinglotUsecaseImpl_Unpark_ClosesTicket(t *testing.T) {
+	layoutTemplate := [][]string{
+		{"B-1"},
+	}
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	u := NewParkingLotUsecase(1, 1, 1, layoutTemplate, WithClock(func() time.Time { return now }))
+	impl := u.(*parkinglotUsecaseImpl)
+
+	ticket, err := impl.Park(constants.Bicycle, "BIKE123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := impl.Unpark(ticket.SpotID, "BIKE123"); err != nil {
+		t.Fatalf("Unpark failed: %v", err)
+	}
+
+	if _, err := impl.UnparkTicket(ticket.ID); err == nil {
+		t.Errorf("expected ticket closed by Unpark to be rejected, got nil")
+	}
+	history, _ := impl.VehicleHistory("BIKE123")
+	if got := history[0].Duration(); got != time.Hour {
+		t.Errorf("expected 1h duration, got %v", got)
+	}
+}
+
+type stubPricer struct {
+	fee domain.Fee
+	err error
+}
+
+func (p stubPricer) Price(ticket domain.Ticket) (domain.Fee, error) {
+	return p.fee, p.err
+}
+
+func TestParkinglotUsecaseImpl_Unpark_Pricing(t *testing.T) {
+	layoutTemplate := [][]string{
+		{"A-1"},
+	}
+	fee := domain.Fee{Amount: 6000, Currency: "IDR"}
+	u := NewParkingLotUsecase(1, 1, 1, layoutTemplate, WithPricer(stubPricer{fee: fee}))
+	impl := u.(*parkinglotUsecaseImpl)
+
+	ticket, err := impl.Park(constants.Automobile, "CAR123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	receipt, err := impl.Unpark(ticket.SpotID, "CAR123")
+	if err != nil {
+		t.Fatalf("Unpark failed: %v", err)
+	}
+	if receipt.Fee != fee || receipt.Ticket.ID != ticket.ID || receipt.Ticket.Active() {
+		t.Errorf("unexpected receipt: %+v", receipt)
+	}
+}
+
+func TestParkinglotUsecaseImpl_Unpark_PricingFailureKeepsVehicleParked(t *testing.T) {
+	layoutTemplate := [][]string{
+		{"A-1"},
+	}
+	u := NewParkingLotUsecase(1, 1, 1, layoutTemplate, WithPricer(stubPricer{err: errors.New("no tariff")}))
+	impl := u.(*parkinglotUsecaseImpl)
+
+	ticket, err := impl.Park(constants.Automobile, "CAR123")
+	if err != nil {
+		t.Fatalf("Park failed: %v", err)
+	}
+	if _, err := impl.UnparkTicket(ticket.ID); err == nil {
+		t.Fatalf("expected pricing error, got nil")
+	}
+
+	if found, _ := impl.SearchVehicle("CAR123"); !found.Parked {
+		t.Errorf("vehicle should still be parked after failed pricing")
+	}
+	if got := impl.AvailableSpot(constants.Automobile); got != 0 {
+		t.Errorf("spot should stay occupied, got %d available", got)
+	}
+}
+
+func TestParkinglotUsecaseImpl_RefundTicket_NoGateway(t *testing.T) {
+	entry := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	paid := domain.Ticket{
+		ID:            domain.TicketID(1),
+		VehicleNumber: "CAR123",
+		VehicleType:   constants.Automobile,
+		SpotID:        "0-0-0",
+		EntryTime:     entry,
+		ExitTime:      entry.Add(time.Hour),
+		Fee:           domain.Fee{Amount: 3000},
+		PaymentID:     "PAY000001",
+	}
+	u, err := NewParkingLotUsecaseFromSnapshot(&domain.Snapshot{
+		Version:   domain.SnapshotVersion,
+		Floors:    [][][]string{{{"A-1"}}},
+		TicketSeq: 1,
+		Tickets:   []domain.Ticket{paid},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.RefundTicket(paid.ID); !errors.Is(err, ErrPaymentsDisabled) {
+		t.Errorf("RefundTicket without a gateway = %v, want ErrPaymentsDisabled", err)
+	}
+}
+
+func newBenchmarkLot(b *testing.B) ParkinglotUsecase {
+	b.Helper()
+	const floors, rows, columns = 50, 100, 100
+	layoutTemplate := make([][]string, rows)
+	for r := range layoutTemplate {
+		layoutTemplate[r] = make([]string, columns)
+		for c := range layoutTemplate[r] {
+			layoutTemplate[r][c] = "A-1"
+		}
+	}
+	return NewParkingLotUsecase(floors, rows, columns, layoutTemplate)
+}
+
+func BenchmarkPark(b *testing.B) {
+	u := newBenchmarkLot(b)
+	capacity := u.AvailableSpot(constants.Automobile)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if i > 0 && i%capacity == 0 {
+			b.StopTimer()
+			u = newBenchmarkLot(b)
+			b.StartTimer()
+		}
+		if _, err := u.Park(constants.Automobile, strconv.Itoa(i)); err != nil {
+			b.Fatalf("Park failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkParkUnpark(b *testing.B) {
+	u := newBenchmarkLot(b)
+	half := u.AvailableSpot(constants.Automobile) / 2
+	spotIDs := make([]string, half)
+	for i := range spotIDs {
+		ticket, err := u.Park(constants.Automobile, strconv.Itoa(i))
+		if err != nil {
+			b.Fatalf("Park failed: %v", err)
+		}
+		spotID := ticket.SpotID
+		spotIDs[i] = spotID
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		slot := i % half
+		vehicle := strconv.Itoa(slot)
+		if _, err := u.Unpark(spotIDs[slot], vehicle); err != nil {
+			b.Fatalf("Unpark failed: %v", err)
+		}
+		ticket, err := u.Park(constants.Automobile, vehicle)
+		if err != nil {
+			b.Fatalf("Park failed: %v", err)
+		}
+		spotID := ticket.SpotID
+		spotIDs[slot] = spotID
+	}
+}
+
+func TestParkinglotUsecaseImpl_Errors(t *testing.T) {
+	newLot := func() ParkinglotUsecase {
+		u := NewParkingLotUsecase(1, 1, 2, [][]string{{"B-1", "A-1"}})
+		if _, err := u.Park(constants.Bicycle, "BIKE1"); err != nil {
+			t.Fatalf("Park failed: %v", err)
+		}
+		return u
+	}
+
+	tests := []struct {
+		name   string
+		call   func(u ParkinglotUsecase) error
+		want   error
+		kind   ErrorKind
+		code   string
+		spotID string
+	}{
+		{"AlreadyParked", func(u ParkinglotUsecase) error {
+			_, err := u.Park(constants.Bicycle, "BIKE1")
+			return err
+		}, ErrAlreadyParked, KindAlreadyExists, "already_parked", "0-0-0"},
+		{"LotFull", func(u ParkinglotUsecase) error {
+			_, err := u.Park(constants.Bicycle, "BIKE2")
+			return err
+		}, ErrLotFull, KindExhausted, "lot_full", ""},
+		{"UnknownVehicleType", func(u ParkinglotUsecase) error {
+			_, err := u.Park(constants.VehicleType("Z"), "X")
+			return err
+		}, ErrUnknownVehicleType, KindInvalid, "unknown_vehicle_type", ""},
+		{"InvalidSpotID", func(u ParkinglotUsecase) error {
+			_, err := u.Unpark("0-0", "BIKE1")
+			return err
+		}, ErrInvalidSpotID, KindInvalid, "invalid_spot_id", "0-0"},
+		{"SpotNotFound", func(u ParkinglotUsecase) error {
+			_, err := u.Unpark("0-3-0", "BIKE1")
+			return err
+		}, ErrSpotNotFound, KindNotFound, "spot_not_found", "0-3-0"},
+		{"VehicleMismatch", func(u ParkinglotUsecase) error {
+			_, err := u.Unpark("0-0-0", "BIKE2")
+			return err
+		}, ErrVehicleMismatch, KindNotFound, "vehicle_mismatch", "0-0-0"},
+		{"TicketNotFound", func(u ParkinglotUsecase) error {
+			_, err := u.UnparkTicket("T99999999")
+			return err
+		}, ErrTicketNotFound, KindNotFound, "ticket_not_found", ""},
+		{"VehicleNotFound", func(u ParkinglotUsecase) error {
+			_, err := u.SearchVehicle("NOPE")
+			return err
+		}, ErrVehicleNotFound, KindNotFound, "vehicle_not_found", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(newLot())
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			var perr *ParkingError
+			if !errors.As(err, &perr) {
+				t.Fatalf("%v is not a *ParkingError", err)
+			}
+			if perr.SpotID != tt.spotID {
+				t.Errorf("SpotID = %q, want %q", perr.SpotID, tt.spotID)
+			}
+			if got := KindOf(err); got != tt.kind {
+				t.Errorf("KindOf = %d, want %d", got, tt.kind)
+			}
+			if got := CodeOf(err); got != tt.code {
+				t.Errorf("CodeOf = %q, want %q", got, tt.code)
+			}
+		})
+	}
+
+	if got := CodeOf(errors.New("disk on fire")); got != "internal" {
+		t.Errorf("CodeOf(unknown) = %q, want internal", got)
+	}
+}
+
+func TestParkinglotUsecaseImpl_Unpark_SpotLabels(t *testing.T) {
+	labels := domain.SpotLabels{FloorPrefix: "L", Base: 1}
+	u := NewParkingLotUsecase(2, 2, 2, [][]string{{"B-1", "B-1"}, {"B-1", "B-1"}}, WithSpotLabels(labels))
+
+	for _, vehicle := range []string{"BIKE1", "BIKE2", "BIKE3"} {
+		if _, err := u.Park(constants.Bicycle, vehicle); err != nil {
+			t.Fatalf("Park failed: %v", err)
+		}
+	}
+	if _, err := u.Unpark("L1-B-1", "BIKE3"); err != nil {
+		t.Errorf("unpark by label: %v", err)
+	}
+	if _, err := u.Unpark("0-0-1", "BIKE2"); err != nil {
+		t.Errorf("unpark by canonical ID: %v", err)
+	}
+	if _, err := u.Unpark("L1-A-1", "BIKE2"); !errors.Is(err, ErrVehicleMismatch) {
+		t.Errorf("got %v, want ErrVehicleMismatch", err)
+	}
+	if _, err := u.Unpark("L3-A-1", "BIKE1"); !errors.Is(err, ErrSpotNotFound) {
+		t.Errorf("got %v, want ErrSpotNotFound", err)
+	}
+	if _, err := u.Unpark("Level1-A-1", "BIKE1"); !errors.Is(err, ErrInvalidSpotID) {
+		t.Errorf("got %v, want ErrInvalidSpotID", err)
+	}
+
+	for in, want := range map[string]string{"1-1-0": "L2-B-1", "0-0-0..1": "L1-A-1..2", "nowhere": "nowhere", "": ""} {
+		if got := u.SpotLabel(in); got != want {
+			t.Errorf("SpotLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := NewParkingLotUsecase(1, 1, 1, [][]string{{"B-1"}}).SpotLabel("0-0-0"); got != "0-0-0" {
+		t.Errorf("SpotLabel without labels = %q", got)
+	}
+	if _, err := NewParkingLotUsecaseFromFloorsE([][][]string{{{"B-1"}}}, WithSpotLabels(domain.SpotLabels{Rows: []string{"A-1"}})); err == nil {
+		t.Error("invalid labels accepted")
+	}
+}
+
+func TestParkinglotUsecaseImpl_RegisteredType(t *testing.T) {
+	const compact constants.VehicleType = "C"
+	if _, err := NewParkingLotUsecaseFromFloorsE([][][]string{{{"C9-1"}}}); err == nil {
+		t.Fatal("unregistered spot type accepted")
+	}
+	before := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1"}}})
+	if _, err := before.Park("C9", "MINI2"); !errors.Is(err, ErrUnknownVehicleType) {
+		t.Errorf("got %v, want ErrUnknownVehicleType", err)
+	}
+
+	if _, known := constants.Lookup(compact); !known {
+		if err := constants.Register(constants.TypeSpec{Type: compact, Name: "compact"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	u, err := NewParkingLotUsecaseFromFloorsE([][][]string{{{"C-1", "C-0", "A-1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.AvailableSpot(compact); got != 1 {
+		t.Errorf("AvailableSpot(C) = %d, want 1", got)
+	}
+	ticket, err := u.Park(compact, "MINI1")
+	if err != nil || ticket.SpotID != "0-0-0" {
+		t.Errorf("Park(C) = %+v, %v", ticket, err)
+	}
+}
+
+func TestParkinglotUsecaseImpl_Park_Fallback(t *testing.T) {
+	compat := constants.Compatibility{
+		constants.Motorcycle: {constants.Motorcycle, constants.Automobile},
+		constants.Bicycle:    {constants.Bicycle, constants.Motorcycle},
+	}
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"B-1", "M-1", "A-1"}}}, WithCompatibility(compat))
+
+	tests := []struct {
+		vt       constants.VehicleType
+		number   string
+		spotID   string
+		spotType constants.VehicleType
+		err      error
+	}{
+		{constants.Motorcycle, "MOTO1", "0-0-1", constants.Motorcycle, nil},
+		{constants.Motorcycle, "MOTO2", "0-0-2", constants.Automobile, nil},
+		{constants.Bicycle, "BIKE1", "0-0-0", constants.Bicycle, nil},
+		{constants.Bicycle, "BIKE2", "", "", ErrLotFull},
+		{constants.Automobile, "CAR1", "", "", ErrLotFull},
+	}
+	for _, tt := range tests {
+		ticket, err := u.Park(tt.vt, tt.number)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("Park(%s, %s): got %v, want %v", tt.vt, tt.number, err, tt.err)
+		}
+		if ticket.SpotID != tt.spotID || ticket.SpotType != tt.spotType {
+			t.Errorf("Park(%s, %s) = spot %s of type %s, want %s of type %s", tt.vt, tt.number, ticket.SpotID, ticket.SpotType, tt.spotID, tt.spotType)
+		}
+	}
+
+	receipt, err := u.Unpark("0-0-2", "MOTO2")
+	if err != nil || receipt.Ticket.SpotType != constants.Automobile {
+		t.Fatalf("Unpark = %+v, %v", receipt, err)
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 1 {
+		t.Errorf("car spot not returned to its own pool: AvailableSpot(A) = %d", got)
+	}
+	u.Park(constants.Motorcycle, "MOTO3")
+
+	restored, err := NewParkingLotUsecaseFromSnapshot(u.Snapshot())
+	if err != nil {
+		t.Fatalf("restore fallback ticket: %v", err)
+	}
+	if location, err := restored.SearchVehicle("MOTO3"); err != nil || location.SpotID != "0-0-2" {
+		t.Errorf("SearchVehicle(MOTO3) = %+v, %v", location, err)
+	}
+}
+
+func TestParkinglotUsecaseImpl_Park_MultiSpot(t *testing.T) {
+	const bus constants.VehicleType = "BUS"
+	if _, known := constants.Lookup(bus); !known {
+		if err := constants.Register(constants.TypeSpec{Type: bus, Name: "bus", Spots: 3}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1", "A-1", "#", "A-1", "A-1", "A-1", "A-1"}}},
+		WithCompatibility(constants.Compatibility{bus: {constants.Automobile}}))
+
+	u.Park(constants.Automobile, "CAR1")
+	ticket, err := u.Park(bus, "BUS1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.SpotID != "0-0-3" || ticket.SpotCount != 3 || ticket.SpotRange() != "0-0-3..5" {
+		t.Errorf("Park(BUS) = %+v, want spots 0-0-3..5", ticket)
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 2 {
+		t.Errorf("AvailableSpot(A) = %d, want 2", got)
+	}
+	if _, err := u.Park(bus, "BUS2"); !errors.Is(err, ErrLotFull) {
+		t.Errorf("Park(BUS) without three adjacent spots: got %v, want ErrLotFull", err)
+	}
+
+	restored, err := NewParkingLotUsecaseFromSnapshot(u.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lot := range []ParkinglotUsecase{u, restored} {
+		location, err := lot.SearchVehicle("BUS1")
+		if err != nil || location.SpotID != "0-0-3" || location.SpotRange != "0-0-3..5" {
+			t.Errorf("SearchVehicle(BUS1) = %+v, %v", location, err)
+		}
+	}
+
+	if _, err := u.Unpark("0-0-5", "BUS1"); err != nil {
+		t.Fatalf("unpark from the last spot of the bus: %v", err)
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 5 {
+		t.Errorf("AvailableSpot(A) after unpark = %d, want 5", got)
+	}
+	if _, err := u.Unpark("0-0-0", "BUS1"); !errors.Is(err, ErrVehicleMismatch) {
+		t.Errorf("unpark twice: got %v, want ErrVehicleMismatch", err)
+	}
+}
@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"submit_do_it/domain"
+)
+
+// ParkingRepository stores a lot's layout, its open tickets and the history of every
+// vehicle. The usecase keeps spots and the free-spot index in memory and consults the
+// repository for tickets. Each Save is atomic: if it fails nothing is stored.
+// store.SQLiteRepository implements it on disk; without one the lot's own maps are used.
+type ParkingRepository interface {
+	// Layout returns the code grid of every floor.
+	Layout() ([][][]string, error)
+	// TicketSeq returns the number of the last ticket issued.
+	TicketSeq() (uint64, error)
+	OpenTickets() ([]domain.Ticket, error)
+
+	// Ticket returns ErrTicketNotFound for unknown IDs.
+	Ticket(id string) (domain.Ticket, error)
+	// OpenTicket returns ErrVehicleNotFound if the vehicle is not parked.
+	OpenTicket(vehicleNumber string) (domain.Ticket, error)
+	// History returns the tickets of a vehicle, oldest first.
+	History(vehicleNumber string) ([]domain.Ticket, error)
+
+	// SaveParked stores a new open ticket, failing with ErrAlreadyParked if the
+	// vehicle already has one. A ticket with a ReservationID also marks that reservation
+	// fulfilled.
+	SaveParked(ticket domain.Ticket) error
+	// SaveUnparked stores the exit time, fee and payment of a closed ticket.
+	SaveUnparked(ticket domain.Ticket) error
+	SaveRefunded(ticketID string) error
+	// SaveActive stores whether the given spots take new vehicles.
+	SaveActive(spotIDs []string, active bool) error
+
+	// ReservationSeq returns the number of the last reservation made.
+	ReservationSeq() (uint64, error)
+	// OpenReservations returns the reservations still booked or held.
+	OpenReservations() ([]domain.Reservation, error)
+	// SaveReservation stores a new reservation, or the new status and held spot of a known
+	// one.
+	SaveReservation(r domain.Reservation) error
+}
+
+// memoryRepository keeps tickets in the maps of the lot, so snapshots and the event log see
+// them. Callers must hold pl.Mutx.
+type memoryRepository struct {
+	pl *domain.ParkingLot
+}
+
+func (r memoryRepository) Layout() ([][][]string, error) {
+	floors := make([][][]string, len(r.pl.Layout))
+	for f, floor := range r.pl.Layout {
+		floors[f] = make([][]string, len(floor))
+		for row, spots := range floor {
+			floors[f][row] = make([]string, len(spots))
+			for c, spot := range spots {
+				floors[f][row][c] = spot.Code()
+			}
+		}
+	}
+	return floors, nil
+}
+
+func (r memoryRepository) TicketSeq() (uint64, error) {
+	return r.pl.TicketSeq, nil
+}
+
+func (r memoryRepository) OpenTickets() ([]domain.Ticket, error) {
+	tickets := make([]domain.Ticket, 0, len(r.pl.VehicleMap))
+	for vehicleNumber := range r.pl.VehicleMap {
+		history := r.pl.History[vehicleNumber]
+		tickets = append(tickets, *history[len(history)-1])
+	}
+	return tickets, nil
+}
+
+func (r memoryRepository) Ticket(id string) (domain.Ticket, error) {
+	ticket, ok := r.pl.Tickets[id]
+	if !ok {
+		return domain.Ticket{}, ErrTicketNotFound
+	}
+	return *ticket, nil
+}
+
+func (r memoryRepository) OpenTicket(vehicleNumber string) (domain.Ticket, error) {
+	if _, parked := r.pl.VehicleMap[vehicleNumber]; !parked {
+		return domain.Ticket{}, ErrVehicleNotFound
+	}
+	history := r.pl.History[vehicleNumber]
+	return *history[len(history)-1], nil
+}
+
+func (r memoryRepository) History(vehicleNumber string) ([]domain.Ticket, error) {
+	tickets := r.pl.History[vehicleNumber]
+	history := make([]domain.Ticket, len(tickets))
+	for i, ticket := range tickets {
+		history[i] = *ticket
+	}
+	return history, nil
+}
+
+func (r memoryRepository) SaveParked(ticket domain.Ticket) error {
+	if _, parked := r.pl.VehicleMap[ticket.VehicleNumber]; parked {
+		return ErrAlreadyParked
+	}
+	stored := &ticket
+	r.pl.VehicleMap[ticket.VehicleNumber] = ticket.SpotID
+	r.pl.History[ticket.VehicleNumber] = append(r.pl.History[ticket.VehicleNumber], stored)
+	r.pl.Tickets[ticket.ID] = stored
+	return nil
+}
+
+func (r memoryRepository) SaveUnparked(ticket domain.Ticket) error {
+	stored, ok := r.pl.Tickets[ticket.ID]
+	if !ok {
+		return ErrTicketNotFound
+	}
+	*stored = ticket
+	delete(r.pl.VehicleMap, ticket.VehicleNumber)
+	return nil
+}
+
+// SaveActive has nothing to do: Layout reads the flags from the spots themselves.
+func (r memoryRepository) SaveActive(spotIDs []string, active bool) error {
+	return nil
+}
+
+func (r memoryRepository) ReservationSeq() (uint64, error) {
+	return r.pl.ReservationSeq, nil
+}
+
+func (r memoryRepository) OpenReservations() ([]domain.Reservation, error) {
+	reservations := make([]domain.Reservation, 0, len(r.pl.Reservations))
+	for _, reservation := range r.pl.Reservations {
+		reservations = append(reservations, *reservation)
+	}
+	return reservations, nil
+}
+
+// SaveReservation has nothing to do: the usecase keeps open reservations in the lot itself,
+// where snapshots see them.
+func (r memoryRepository) SaveReservation(reservation domain.Reservation) error {
+	return nil
+}
+
+func (r memoryRepository) SaveRefunded(ticketID string) error {
+	stored, ok := r.pl.Tickets[ticketID]
+	if !ok {
+		return ErrTicketNotFound
+	}
+	stored.Refunded = true
+	return nil
+}
@@ -0,0 +1,257 @@
+package usecases
+
+import (
+	"fmt"
+	"sort"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"time"
+)
+
+// DefaultReservationGrace is how long a held spot waits for a late vehicle.
+const DefaultReservationGrace = 15 * time.Minute
+
+// Reserve books spots for a vehicle between start and end. Reservations use the most
+// preferred spot type of the vehicle and are refused with ErrLotFull once the reservations
+// overlapping the window would need more spots than the lot has. When the window opens a
+// spot is held: it no longer counts as available and only the vehicle may park there. The
+// hold ends when the vehicle parks, or as a no-show once the grace period after start, or
+// the window, is over.
+func (pu *parkinglotUsecaseImpl) Reserve(vehicleType constants.VehicleType, vehicleNumber string, start, end time.Time) (domain.Reservation, error) {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
+
+	r := domain.Reservation{
+		VehicleNumber: vehicleNumber,
+		VehicleType:   vehicleType,
+		SpotType:      pu.compat.SpotTypes(vehicleType)[0],
+		Start:         start,
+		End:           end,
+		Status:        domain.ReservationBooked,
+	}
+	if _, known := pu.pl.AvailableSpots[vehicleType]; !known {
+		return domain.Reservation{}, reservationError("reserve", r, ErrUnknownVehicleType)
+	}
+	if err := pu.syncReservations(); err != nil {
+		return domain.Reservation{}, reservationError("reserve", r, err)
+	}
+	if !start.Before(end) || !pu.now().Before(pu.holdDeadline(&r)) {
+		return domain.Reservation{}, reservationError("reserve", r, ErrInvalidReservation)
+	}
+	if spec, ok := constants.Lookup(vehicleType); ok && spec.SpotCount() > 1 {
+		r.SpotCount = spec.SpotCount()
+	}
+
+	needed := max(r.SpotCount, 1)
+	for _, other := range pu.pl.Reservations {
+		if !other.Overlaps(&r) {
+			continue
+		}
+		if other.VehicleNumber == vehicleNumber {
+			return domain.Reservation{}, reservationError("reserve", *other, ErrAlreadyReserved)
+		}
+		if other.SpotType == r.SpotType {
+			needed += max(other.SpotCount, 1)
+		}
+	}
+	if needed > pu.capacity(r.SpotType) {
+		return domain.Reservation{}, reservationError("reserve", r, ErrLotFull)
+	}
+
+	if err := pu.addReservation(&r); err != nil {
+		return domain.Reservation{}, reservationError("reserve", r, err)
+	}
+
+	// A window that is already open holds a spot right away.
+	if err := pu.syncReservations(); err != nil {
+		return domain.Reservation{}, reservationError("reserve", r, err)
+	}
+	return r, nil
+}
+
+// addReservation numbers a booked reservation and stores it. Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) addReservation(r *domain.Reservation) error {
+	r.ID = domain.ReservationID(pu.pl.ReservationSeq + 1)
+	if err := pu.recordEvent(domain.Event{Type: domain.EventReserved, Reservation: *r}); err != nil {
+		return err
+	}
+	if err := pu.repo.SaveReservation(*r); err != nil {
+		return pu.abort(fmt.Errorf("save reservation: %w", err))
+	}
+	pu.pl.ReservationSeq++
+	pu.pl.Reservations[r.ID] = r
+	return nil
+}
+
+// CancelReservation releases an open reservation and any spot it holds.
+func (pu *parkinglotUsecaseImpl) CancelReservation(id string) error {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
+
+	if err := pu.syncReservations(); err != nil {
+		return reservationError("cancel reservation", domain.Reservation{ID: id}, err)
+	}
+	r, ok := pu.pl.Reservations[id]
+	if !ok {
+		return reservationError("cancel reservation", domain.Reservation{ID: id}, ErrReservationNotFound)
+	}
+	if err := pu.releaseReservation(r, domain.ReservationCancelled); err != nil {
+		return reservationError("cancel reservation", *r, err)
+	}
+	return nil
+}
+
+// Reservation returns an open reservation. Fulfilled, cancelled and no-show reservations
+// are not kept by the lot and return ErrReservationNotFound.
+func (pu *parkinglotUsecaseImpl) Reservation(id string) (domain.Reservation, error) {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
+
+	if err := pu.syncReservations(); err != nil {
+		return domain.Reservation{}, reservationError("reservation", domain.Reservation{ID: id}, err)
+	}
+	r, ok := pu.pl.Reservations[id]
+	if !ok {
+		return domain.Reservation{}, reservationError("reservation", domain.Reservation{ID: id}, ErrReservationNotFound)
+	}
+	return *r, nil
+}
+
+// syncReservations holds spots for reservations whose window has opened and releases the
+// ones whose vehicle did not arrive in time, then offers what is still free to the
+// waitlist. Operations that hand out or free spots run it, so holds follow the
+// clock without a background goroutine. A reservation that finds no free spot stays
+// booked and takes the next one freed, ahead of the waitlist. Callers must hold pl.Mutx
+// for writing.
+func (pu *parkinglotUsecaseImpl) syncReservations() error {
+	if err := pu.syncHolds(); err != nil {
+		return err
+	}
+	return pu.serveWaitlist()
+}
+
+func (pu *parkinglotUsecaseImpl) syncHolds() error {
+	open := make([]*domain.Reservation, 0, len(pu.pl.Reservations))
+	for _, r := range pu.pl.Reservations {
+		open = append(open, r)
+	}
+	sort.Slice(open, func(i, j int) bool {
+		if !open[i].Start.Equal(open[j].Start) {
+			return open[i].Start.Before(open[j].Start)
+		}
+		return open[i].ID < open[j].ID
+	})
+
+	now := pu.now()
+	for _, r := range open {
+		var err error
+		switch {
+		case !now.Before(pu.holdDeadline(r)):
+			err = pu.releaseReservation(r, domain.ReservationNoShow)
+		case r.Status == domain.ReservationBooked && !now.Before(r.Start):
+			err = pu.holdReservation(r)
+		}
+		if err != nil {
+			return fmt.Errorf("reservation %s: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// holdDeadline is when a reservation whose vehicle has not parked is released.
+func (pu *parkinglotUsecaseImpl) holdDeadline(r *domain.Reservation) time.Time {
+	if r.Waitlisted {
+		return r.End
+	}
+	deadline := r.Start.Add(pu.grace)
+	if r.End.Before(deadline) {
+		return r.End
+	}
+	return deadline
+}
+
+// holdReservation picks spots for r and takes them out of AvailableSpots, or leaves r
+// booked if none are free. Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) holdReservation(r *domain.Reservation) error {
+	spots := pu.selectSpots(r.SpotType, max(r.SpotCount, 1))
+	if spots == nil {
+		return nil
+	}
+
+	held := *r
+	held.Status = domain.ReservationHeld
+	held.SpotID = spots[0].ID()
+	if err := pu.recordEvent(domain.Event{Type: domain.EventReservationHeld, Reservation: held}); err != nil {
+		return err
+	}
+	if err := pu.repo.SaveReservation(held); err != nil {
+		return pu.abort(fmt.Errorf("save reservation: %w", err))
+	}
+
+	if _, err := pu.pl.HoldSpots(&held); err != nil {
+		if restoreErr := pu.repo.SaveReservation(*r); restoreErr != nil {
+			err = fmt.Errorf("%w; restore reservation: %v", err, restoreErr)
+		}
+		return pu.abort(err)
+	}
+	*r = held
+	for _, spot := range spots {
+		pu.pl.AvailableSpots[spot.SpotType].Remove(spot)
+	}
+	return nil
+}
+
+// releaseReservation closes r with status and returns any spot it held to AvailableSpots.
+// Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) releaseReservation(r *domain.Reservation, status domain.ReservationStatus) error {
+	released := *r
+	released.Status = status
+	if err := pu.recordEvent(domain.Event{Type: domain.EventReservationReleased, Reservation: released}); err != nil {
+		return err
+	}
+	if err := pu.repo.SaveReservation(released); err != nil {
+		return pu.abort(fmt.Errorf("save reservation: %w", err))
+	}
+
+	if r.Status == domain.ReservationHeld {
+		for _, spot := range pu.pl.ReleaseSpots(r) {
+			if spot.Active && !spot.Occupied {
+				pu.pl.AvailableSpots[spot.SpotType].Add(spot)
+			}
+		}
+	}
+	delete(pu.pl.Reservations, r.ID)
+	return nil
+}
+
+// heldReservation returns the reservation holding spots for the vehicle, if any. Callers
+// must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) heldReservation(vehicleType constants.VehicleType, vehicleNumber string) *domain.Reservation {
+	for _, r := range pu.pl.Reservations {
+		if r.Status == domain.ReservationHeld && r.VehicleNumber == vehicleNumber && r.VehicleType == vehicleType {
+			return r
+		}
+	}
+	return nil
+}
+
+// capacity counts the active spots of spotType in the layout.
+func (pu *parkinglotUsecaseImpl) capacity(spotType constants.VehicleType) int {
+	n := 0
+	for _, floorSpots := range pu.activeSpots[spotType] {
+		n += floorSpots
+	}
+	return n
+}
+
+// reservationError wraps err with what is known about the reservation involved in op.
+func reservationError(op string, r domain.Reservation, err error) error {
+	return &ParkingError{
+		Op:            op,
+		VehicleNumber: r.VehicleNumber,
+		VehicleType:   r.VehicleType,
+		SpotID:        r.SpotID,
+		ReservationID: r.ID,
+		Err:           err,
+	}
+}
@@ -0,0 +1,242 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+)
+
+func TestParkinglotUsecaseImpl_Reserve(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	at := func(hour, min int) time.Time { return time.Date(2024, 5, 1, hour, min, 0, 0, time.UTC) }
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1", "A-1"}}},
+		WithClock(func() time.Time { return clock }),
+		WithReservationGrace(10*time.Minute))
+
+	first, err := u.Reserve(constants.Automobile, "CAR1", at(9, 0), at(18, 0))
+	if err != nil || first.ID != "R00000001" || first.Status != domain.ReservationBooked {
+		t.Fatalf("Reserve = %+v, %v", first, err)
+	}
+	second, err := u.Reserve(constants.Automobile, "CAR2", at(9, 0), at(12, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		vt         constants.VehicleType
+		number     string
+		start, end time.Time
+		want       error
+	}{
+		{"Full", constants.Automobile, "CAR3", at(11, 0), at(13, 0), ErrLotFull},
+		{"SameVehicle", constants.Automobile, "CAR1", at(17, 0), at(19, 0), ErrAlreadyReserved},
+		{"EmptyWindow", constants.Automobile, "CAR3", at(13, 0), at(13, 0), ErrInvalidReservation},
+		{"Past", constants.Automobile, "CAR3", at(6, 0), at(7, 0), ErrInvalidReservation},
+		{"UnknownType", "Z", "CAR3", at(13, 0), at(14, 0), ErrUnknownVehicleType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := u.Reserve(tt.vt, tt.number, tt.start, tt.end); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if _, err := u.Reserve(constants.Automobile, "CAR3", at(12, 0), at(13, 0)); err != nil {
+		t.Errorf("reserve after a window closes: %v", err)
+	}
+
+	if got := u.AvailableSpot(constants.Automobile); got != 2 {
+		t.Errorf("AvailableSpot(A) before the window = %d, want 2", got)
+	}
+	clock = at(9, 0)
+	if got := u.AvailableSpot(constants.Automobile); got != 0 {
+		t.Errorf("AvailableSpot(A) during the hold = %d, want 0", got)
+	}
+	if _, err := u.Park(constants.Automobile, "WALKIN"); !errors.Is(err, ErrLotFull) {
+		t.Errorf("walk-in parked in a held spot: %v", err)
+	}
+	held, err := u.Reservation(first.ID)
+	if err != nil || held.Status != domain.ReservationHeld || held.SpotID != "0-0-0" {
+		t.Errorf("Reservation(%s) = %+v, %v", first.ID, held, err)
+	}
+
+	clock = at(9, 5)
+	ticket, err := u.Park(constants.Automobile, "CAR1")
+	if err != nil || ticket.SpotID != "0-0-0" || ticket.ReservationID != first.ID {
+		t.Fatalf("Park(CAR1) = %+v, %v", ticket, err)
+	}
+	if _, err := u.Reservation(first.ID); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("fulfilled reservation still open: %v", err)
+	}
+
+	clock = at(9, 10)
+	if got := u.AvailableSpot(constants.Automobile); got != 1 {
+		t.Errorf("AvailableSpot(A) after the no-show = %d, want 1", got)
+	}
+	if _, err := u.Park(constants.Automobile, "CAR2"); err != nil {
+		t.Errorf("late vehicle cannot park as a walk-in: %v", err)
+	}
+	if _, err := u.Reservation(second.ID); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("no-show reservation still open: %v", err)
+	}
+}
+
+func TestParkinglotUsecaseImpl_Reserve_WaitsForSpot(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1"}}}, WithClock(func() time.Time { return clock }))
+	parked, _ := u.Park(constants.Automobile, "CAR1")
+
+	r, err := u.Reserve(constants.Automobile, "CAR2", clock, clock.Add(time.Hour))
+	if err != nil || r.Status != domain.ReservationBooked {
+		t.Fatalf("Reserve with every spot taken = %+v, %v", r, err)
+	}
+	if _, err := u.UnparkTicket(parked.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Park(constants.Automobile, "CAR3"); !errors.Is(err, ErrLotFull) {
+		t.Errorf("freed spot went to a walk-in instead of the reservation: %v", err)
+	}
+	if ticket, err := u.Park(constants.Automobile, "CAR2"); err != nil || ticket.ReservationID != r.ID {
+		t.Errorf("Park(CAR2) = %+v, %v", ticket, err)
+	}
+}
+
+func TestParkinglotUsecaseImpl_AvailableSpot_ReadOnly(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	var events eventSlice
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1", "A-1"}}},
+		WithClock(func() time.Time { return clock }),
+		WithReservationGrace(10*time.Minute),
+		WithEventRecorder(&events))
+	r, err := u.Reserve(constants.Automobile, "CAR1", clock.Add(time.Hour), clock.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := len(events)
+	for _, tt := range []struct {
+		at   time.Time
+		want int
+	}{
+		{r.Start.Add(-time.Minute), 2},
+		{r.Start, 1},
+		{r.Start.Add(10 * time.Minute), 2},
+	} {
+		clock = tt.at
+		if got := u.AvailableSpot(constants.Automobile); got != tt.want {
+			t.Errorf("AvailableSpot(A) at %s = %d, want %d", tt.at.Format("15:04"), got, tt.want)
+		}
+	}
+	if len(events) != recorded {
+		t.Errorf("AvailableSpot recorded %d events", len(events)-recorded)
+	}
+}
+
+// savedReservations remembers the last state of every reservation saved through it.
+type savedReservations struct {
+	ParkingRepository
+	saved map[string]domain.Reservation
+}
+
+func (r savedReservations) SaveReservation(reservation domain.Reservation) error {
+	r.saved[reservation.ID] = reservation
+	return r.ParkingRepository.SaveReservation(reservation)
+}
+
+func TestParkinglotUsecaseImpl_Reserve_HoldFails(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	var events eventSlice
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1"}}},
+		WithClock(func() time.Time { return clock }),
+		WithEventRecorder(&events))
+	impl := u.(*parkinglotUsecaseImpl)
+	repo := savedReservations{impl.repo, make(map[string]domain.Reservation)}
+	impl.repo = repo
+
+	// A spot marked occupied yet still listed as free makes HoldSpots refuse it.
+	impl.pl.Layout[0][0][0].Occupied = true
+	if _, err := u.Reserve(constants.Automobile, "CAR1", clock, clock.Add(time.Hour)); err == nil {
+		t.Fatal("Reserve succeeded with the hold failing")
+	}
+
+	last := events[len(events)-1]
+	if last.Type != domain.EventAborted || events[last.Aborted-1].Type != domain.EventReservationHeld {
+		t.Errorf("events = %+v, want the hold aborted", events)
+	}
+	if r := repo.saved["R00000001"]; r.Status != domain.ReservationBooked || r.SpotID != "" {
+		t.Errorf("saved reservation = %+v, want it booked again", r)
+	}
+}
+
+func TestParkinglotUsecaseImpl_CancelReservation(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1"}}}, WithClock(func() time.Time { return clock }))
+	r, err := u.Reserve(constants.Automobile, "CAR1", clock, clock.Add(time.Hour))
+	if err != nil || r.Status != domain.ReservationHeld {
+		t.Fatalf("Reserve = %+v, %v", r, err)
+	}
+
+	if err := u.CancelReservation(r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := u.AvailableSpot(constants.Automobile); got != 1 {
+		t.Errorf("AvailableSpot(A) after cancelling = %d, want 1", got)
+	}
+	err = u.CancelReservation(r.ID)
+	var perr *ParkingError
+	if !errors.Is(err, ErrReservationNotFound) || !errors.As(err, &perr) || perr.ReservationID != r.ID {
+		t.Errorf("cancel twice: %v", err)
+	}
+}
+
+func TestParkinglotUsecaseImpl_Reserve_Restore(t *testing.T) {
+	floors := [][][]string{{{"A-1", "A-1", "A-1"}}}
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	withClock := WithClock(func() time.Time { return clock })
+	var events eventSlice
+	u := NewParkingLotUsecaseFromFloors(floors, withClock, WithEventRecorder(&events))
+
+	fulfilled, _ := u.Reserve(constants.Automobile, "CAR1", clock, clock.Add(time.Hour))
+	u.Park(constants.Automobile, "CAR1")
+	cancelled, _ := u.Reserve(constants.Automobile, "CAR2", clock, clock.Add(time.Hour))
+	u.CancelReservation(cancelled.ID)
+	held, _ := u.Reserve(constants.Automobile, "CAR3", clock, clock.Add(time.Hour))
+	later, _ := u.Reserve(constants.Automobile, "CAR4", clock.Add(2*time.Hour), clock.Add(3*time.Hour))
+
+	empty := &domain.Snapshot{Version: domain.SnapshotVersion, Floors: floors}
+	replayed, err := NewParkingLotUsecaseFromEvents(empty, events, withClock)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	restored, err := NewParkingLotUsecaseFromSnapshot(u.Snapshot(), withClock)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	for name, lot := range map[string]ParkinglotUsecase{"replayed": replayed, "restored": restored} {
+		if got := lot.AvailableSpot(constants.Automobile); got != 1 {
+			t.Errorf("%s: AvailableSpot(A) = %d, want 1", name, got)
+		}
+		for _, id := range []string{fulfilled.ID, cancelled.ID} {
+			if _, err := lot.Reservation(id); !errors.Is(err, ErrReservationNotFound) {
+				t.Errorf("%s: closed reservation %s open again: %v", name, id, err)
+			}
+		}
+		if r, err := lot.Reservation(held.ID); err != nil || r.Status != domain.ReservationHeld || r.SpotID != "0-0-1" {
+			t.Errorf("%s: Reservation(%s) = %+v, %v", name, held.ID, r, err)
+		}
+		if r, err := lot.Reservation(later.ID); err != nil || r.Status != domain.ReservationBooked {
+			t.Errorf("%s: Reservation(%s) = %+v, %v", name, later.ID, r, err)
+		}
+		if ticket, err := lot.Park(constants.Automobile, "CAR3"); err != nil || ticket.SpotID != "0-0-1" {
+			t.Errorf("%s: Park(CAR3) = %+v, %v", name, ticket, err)
+		}
+		if r, err := lot.Reserve(constants.Automobile, "CAR5", clock, clock.Add(time.Hour)); err != nil || r.ID != "R00000005" {
+			t.Errorf("%s: next reservation = %+v, %v", name, r, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+)
+
+func TestNewParkingLotUsecaseFromSnapshot_RoundTrip(t *testing.T) {
+	u := NewParkingLotUsecaseFromFloors([][][]string{
+		{{"B-1", "A-1", "A-1"}},
+		{{"#", "A-1", "M-0"}},
+	})
+	first, _ := u.Park(constants.Automobile, "CAR1")
+	u.Park(constants.Automobile, "CAR2")
+	u.Park(constants.Bicycle, "BIKE1")
+	if _, err := u.UnparkTicket(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	u.Park(constants.Automobile, "CAR3")
+
+	snap := u.Snapshot()
+	restored, err := NewParkingLotUsecaseFromSnapshot(snap)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	for _, vt := range constants.Types() {
+		if got, want := restored.AvailableSpot(vt), u.AvailableSpot(vt); got != want {
+			t.Errorf("AvailableSpot(%s) = %d, want %d", vt, got, want)
+		}
+	}
+	history, err := restored.VehicleHistory("CAR1")
+	if err != nil || len(history) != 1 || history[0].Active() {
+		t.Errorf("CAR1 history = %+v, %v", history, err)
+	}
+	if _, err := restored.Park(constants.Automobile, "CAR2"); !errors.Is(err, ErrAlreadyParked) {
+		t.Errorf("park CAR2 again: %v, want ErrAlreadyParked", err)
+	}
+
+	ticket, err := restored.Park(constants.Automobile, "CAR4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ID != "T00000005" {
+		t.Errorf("next ticket = %s, want T00000005", ticket.ID)
+	}
+	if _, err := restored.Unpark("0-0-2", "CAR2"); err != nil {
+		t.Errorf("unpark restored vehicle: %v", err)
+	}
+}
+
+func TestNewParkingLotUsecaseFromSnapshot_Invalid(t *testing.T) {
+	entry := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	open := func(id, vehicle, spot string, vt constants.VehicleType) domain.Ticket {
+		return domain.Ticket{ID: id, VehicleNumber: vehicle, VehicleType: vt, SpotID: spot, EntryTime: entry}
+	}
+	floors := [][][]string{{{"B-1", "A-1", "A-0"}}}
+
+	tests := []struct {
+		name    string
+		snap    domain.Snapshot
+		wantErr string
+	}{
+		{"Version", domain.Snapshot{Version: 99, Floors: floors}, "unsupported version 99"},
+		{"BadLayout", domain.Snapshot{Version: 1, Floors: [][][]string{{{"Q-1"}}}}, "invalid layout"},
+		{"SeqBehind", domain.Snapshot{Version: 1, Floors: floors, TicketSeq: 1, Tickets: []domain.Ticket{
+			open("T00000002", "CAR1", "0-0-1", constants.Automobile),
+		}}, "issued after ticket sequence 1"},
+		{"Duplicate", domain.Snapshot{Version: 1, Floors: floors, TicketSeq: 2, Tickets: []domain.Ticket{
+			open("T00000001", "CAR1", "0-0-1", constants.Automobile),
+			open("T00000001", "CAR2", "0-0-1", constants.Automobile),
+		}}, "duplicate ticket"},
+		{"UnknownSpot", domain.Snapshot{Version: 1, Floors: floors, TicketSeq: 1, Tickets: []domain.Ticket{
+			open("T00000001", "CAR1", "0-3-1", constants.Automobile),
+		}}, "spot 0-3-1 not in layout"},
+		{"WrongType", domain.Snapshot{Version: 1, Floors: floors, TicketSeq: 1, Tickets: []domain.Ticket{
+			open("T00000001", "CAR1", "0-0-0", constants.Automobile),
+		}}, "does not match spot 0-0-0"},
+		{"SpotTwice", domain.Snapshot{Version: 1, Floors: floors, TicketSeq: 2, Tickets: []domain.Ticket{
+			open("T00000001", "CAR1", "0-0-1", constants.Automobile),
+			open("T00000002", "CAR2", "0-0-1", constants.Automobile),
+		}}, "already occupied by CAR1"},
+		{"VehicleTwice", domain.Snapshot{Version: 1, Floors: [][][]string{{{"A-1", "A-1"}}}, TicketSeq: 2, Tickets: []domain.Ticket{
+			open("T00000001", "CAR1", "0-0-0", constants.Automobile),
+			open("T00000002", "CAR1", "0-0-1", constants.Automobile),
+		}}, "more than one open ticket"},
+		{"ExitBeforeEntry", domain.Snapshot{Version: 1, Floors: floors, TicketSeq: 1, Tickets: []domain.Ticket{
+			{ID: "T00000001", VehicleNumber: "CAR1", VehicleType: constants.Automobile, SpotID: "0-0-1", EntryTime: entry, ExitTime: entry.Add(-time.Hour)},
+		}}, "exit time before entry time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParkingLotUsecaseFromSnapshot(&tt.snap)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewParkingLotUsecaseFromEvents_Invalid(t *testing.T) {
+	snap := NewParkingLotUsecase(1, 1, 2, [][]string{{"A-1", "A-1"}}).Snapshot()
+	parked := domain.Ticket{ID: "T00000001", VehicleNumber: "CAR1", VehicleType: constants.Automobile, SpotID: "0-0-0", EntryTime: time.Now()}
+	closed := parked
+	closed.ExitTime = parked.EntryTime.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		events  []domain.Event
+		wantErr string
+	}{
+		{"Gap", []domain.Event{{Seq: 2, Type: domain.EventParked, Ticket: parked}}, "events are missing"},
+		{"UnknownType", []domain.Event{{Seq: 1, Type: "towed", Ticket: parked}}, `unknown event type "towed"`},
+		{"UnparkUnknown", []domain.Event{{Seq: 1, Type: domain.EventUnparked, Ticket: closed}}, "unknown ticket"},
+		{"UnparkTwice", []domain.Event{
+			{Seq: 1, Type: domain.EventParked, Ticket: parked},
+			{Seq: 2, Type: domain.EventUnparked, Ticket: closed},
+			{Seq: 3, Type: domain.EventUnparked, Ticket: closed},
+		}, "ticket already closed"},
+		{"RefundUnpaid", []domain.Event{
+			{Seq: 1, Type: domain.EventParked, Ticket: parked},
+			{Seq: 2, Type: domain.EventRefunded, Ticket: parked},
+		}, "not refundable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParkingLotUsecaseFromEvents(snap, tt.events)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// rejectingRepository fails every save of a new ticket or reservation.
+type rejectingRepository struct {
+	ParkingRepository
+}
+
+var errRejected = errors.New("disk full")
+
+func (rejectingRepository) SaveParked(domain.Ticket) error           { return errRejected }
+func (rejectingRepository) SaveReservation(domain.Reservation) error { return errRejected }
+
+func TestNewParkingLotUsecaseFromEvents_Aborted(t *testing.T) {
+	floors := [][][]string{{{"A-1", "A-1"}}}
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	withClock := WithClock(func() time.Time { return clock })
+	var events eventSlice
+	u := NewParkingLotUsecaseFromFloors(floors, withClock, WithEventRecorder(&events))
+	impl := u.(*parkinglotUsecaseImpl)
+
+	u.Park(constants.Automobile, "CAR1")
+	saved := impl.repo
+	impl.repo = rejectingRepository{saved}
+	if _, err := u.Park(constants.Automobile, "CAR2"); !errors.Is(err, errRejected) {
+		t.Fatalf("Park with the repository failing: %v", err)
+	}
+	if _, err := u.Reserve(constants.Automobile, "CAR3", clock, clock.Add(time.Hour)); !errors.Is(err, errRejected) {
+		t.Fatalf("Reserve with the repository failing: %v", err)
+	}
+	impl.repo = saved
+	if _, err := u.Park(constants.Automobile, "CAR4"); err != nil {
+		t.Fatal(err)
+	}
+
+	var aborted []uint64
+	for _, e := range events {
+		if e.Type == domain.EventAborted {
+			aborted = append(aborted, e.Aborted)
+		}
+	}
+	if len(aborted) != 2 || aborted[0] != 2 || aborted[1] != 4 {
+		t.Errorf("aborted events cancel %v, want [2 4]", aborted)
+	}
+
+	empty := &domain.Snapshot{Version: domain.SnapshotVersion, Floors: floors}
+	replayed, err := NewParkingLotUsecaseFromEvents(empty, events, withClock)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if got := replayed.AvailableSpot(constants.Automobile); got != 0 {
+		t.Errorf("AvailableSpot(A) after replay = %d, want 0", got)
+	}
+	if history, err := replayed.VehicleHistory("CAR2"); err == nil || len(history) != 0 {
+		t.Errorf("CAR2 history after replay = %+v, %v", history, err)
+	}
+	if got, want := replayed.Snapshot().EventSeq, u.Snapshot().EventSeq; got != want {
+		t.Errorf("EventSeq after replay = %d, want %d", got, want)
+	}
+}
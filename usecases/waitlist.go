@@ -0,0 +1,271 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+	"time"
+)
+
+// DefaultWaitlistClaim is how long a spot offered from the waitlist waits for its vehicle.
+const DefaultWaitlistClaim = 15 * time.Minute
+
+// WaitlistOrder decides which waiting vehicle is offered a freed spot first.
+type WaitlistOrder int
+
+const (
+	WaitlistFIFO     WaitlistOrder = iota // first come, first served
+	WaitlistPriority                      // highest priority first, first come first served among equals
+)
+
+// ParseWaitlistOrder reads an order written as "fifo" or "priority", ignoring case.
+func ParseWaitlistOrder(s string) (WaitlistOrder, error) {
+	switch strings.ToLower(s) {
+	case "fifo":
+		return WaitlistFIFO, nil
+	case "priority":
+		return WaitlistPriority, nil
+	}
+	return 0, fmt.Errorf("unknown waitlist order %q, want fifo or priority", s)
+}
+
+// waitlist queues vehicles turned away with ErrLotFull, one queue per vehicle type. It
+// lives in memory only; offers already made are reservations and survive a restart.
+type waitlist struct {
+	order  WaitlistOrder
+	claim  time.Duration
+	seq    uint64
+	queues map[constants.VehicleType][]*waiter
+}
+
+type waiter struct {
+	vehicleNumber string
+	vehicleType   constants.VehicleType
+	priority      int
+	seq           uint64
+	notify        func(domain.Reservation)
+}
+
+func newWaitlist(order WaitlistOrder, claim time.Duration) *waitlist {
+	return &waitlist{order: order, claim: claim, queues: make(map[constants.VehicleType][]*waiter)}
+}
+
+// ahead reports whether a is offered a spot before b.
+func (wl *waitlist) ahead(a, b *waiter) bool {
+	if wl.order == WaitlistPriority && a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+// add queues w and returns its 1-based position in the queue of its vehicle type.
+func (wl *waitlist) add(w *waiter) int {
+	wl.seq++
+	w.seq = wl.seq
+	queue := wl.queues[w.vehicleType]
+	i := sort.Search(len(queue), func(i int) bool { return wl.ahead(w, queue[i]) })
+	queue = append(queue, nil)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = w
+	wl.queues[w.vehicleType] = queue
+	return i + 1
+}
+
+// remove drops the vehicle from its queue and reports whether it was waiting.
+func (wl *waitlist) remove(vehicleNumber string) bool {
+	for vt, queue := range wl.queues {
+		for i, w := range queue {
+			if w.vehicleNumber == vehicleNumber {
+				wl.queues[vt] = append(queue[:i], queue[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (wl *waitlist) waiting(vehicleNumber string) bool {
+	return wl.position(vehicleNumber) > 0
+}
+
+// position returns the 1-based place of the vehicle in the queue of its vehicle type, or
+// 0 if it is not waiting.
+func (wl *waitlist) position(vehicleNumber string) int {
+	for _, queue := range wl.queues {
+		for i, w := range queue {
+			if w.vehicleNumber == vehicleNumber {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// wants reports whether a waiting vehicle could take a spot of spotType, so a spot of it
+// freed now would be offered rather than left free. wl may be nil.
+func (wl *waitlist) wants(spotType constants.VehicleType, compat constants.Compatibility) bool {
+	if wl == nil {
+		return false
+	}
+	for vt, queue := range wl.queues {
+		if len(queue) > 0 && slices.Contains(compat.SpotTypes(vt), spotType) {
+			return true
+		}
+	}
+	return false
+}
+
+// heads returns the first waiter of every vehicle type, in the order they are served.
+func (wl *waitlist) heads() []*waiter {
+	var heads []*waiter
+	for _, queue := range wl.queues {
+		if len(queue) > 0 {
+			heads = append(heads, queue[0])
+		}
+	}
+	sort.Slice(heads, func(i, j int) bool { return wl.ahead(heads[i], heads[j]) })
+	return heads
+}
+
+// JoinWaitlist queues a vehicle for the next spot it fits. When one is free it is held for
+// the vehicle as a reservation lasting the claim window, and notify is called with it by
+// the operation that freed the spot, after the lot is unlocked and before that operation
+// returns. Parking the vehicle before the reservation ends claims the spot;
+// otherwise it goes to the next vehicle in line. The returned position is 0 if the offer
+// was made at once. Requires WithWaitlist.
+func (pu *parkinglotUsecaseImpl) JoinWaitlist(vehicleType constants.VehicleType, vehicleNumber string, priority int, notify func(domain.Reservation)) (int, error) {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
+
+	request := domain.Ticket{VehicleNumber: vehicleNumber, VehicleType: vehicleType}
+	if pu.waitlist == nil {
+		return 0, opError("join waitlist", request, ErrWaitlistDisabled)
+	}
+	if _, known := pu.pl.AvailableSpots[vehicleType]; !known {
+		return 0, opError("join waitlist", request, ErrUnknownVehicleType)
+	}
+	if err := pu.syncReservations(); err != nil {
+		return 0, opError("join waitlist", request, err)
+	}
+	if open, err := pu.repo.OpenTicket(vehicleNumber); err == nil {
+		return 0, opError("join waitlist", open, ErrAlreadyParked)
+	} else if !errors.Is(err, ErrVehicleNotFound) {
+		return 0, opError("join waitlist", request, err)
+	}
+	if r := pu.heldReservation(vehicleType, vehicleNumber); r != nil {
+		return 0, reservationError("join waitlist", *r, ErrAlreadyReserved)
+	}
+	if pu.waitlist.waiting(vehicleNumber) {
+		return 0, opError("join waitlist", request, ErrAlreadyWaiting)
+	}
+
+	position := pu.waitlist.add(&waiter{
+		vehicleNumber: vehicleNumber,
+		vehicleType:   vehicleType,
+		priority:      priority,
+		notify:        notify,
+	})
+	if err := pu.serveWaitlist(); err != nil {
+		return 0, opError("join waitlist", request, err)
+	}
+	if !pu.waitlist.waiting(vehicleNumber) {
+		return 0, nil
+	}
+	return position, nil
+}
+
+// LeaveWaitlist takes a vehicle off the waitlist. A spot already offered to it is kept
+// until its reservation is cancelled or runs out.
+func (pu *parkinglotUsecaseImpl) LeaveWaitlist(vehicleNumber string) error {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
+
+	request := domain.Ticket{VehicleNumber: vehicleNumber}
+	if pu.waitlist == nil {
+		return opError("leave waitlist", request, ErrWaitlistDisabled)
+	}
+	if !pu.waitlist.remove(vehicleNumber) {
+		return opError("leave waitlist", request, ErrVehicleNotFound)
+	}
+	return nil
+}
+
+// WaitlistEntry looks up a vehicle that joined the waitlist. While it waits, the returned
+// position is its place in line; once a spot is offered the position is 0 and the
+// reservation holding the spot is returned. ErrVehicleNotFound means the vehicle is
+// neither waiting nor holding an offer, e.g. because the offer ran out. Requires
+// WithWaitlist.
+func (pu *parkinglotUsecaseImpl) WaitlistEntry(vehicleNumber string) (int, domain.Reservation, error) {
+	pu.pl.Mutx.Lock()
+	defer pu.unlock()
+
+	request := domain.Ticket{VehicleNumber: vehicleNumber}
+	if pu.waitlist == nil {
+		return 0, domain.Reservation{}, opError("waitlist entry", request, ErrWaitlistDisabled)
+	}
+	if err := pu.syncReservations(); err != nil {
+		return 0, domain.Reservation{}, opError("waitlist entry", request, err)
+	}
+	if position := pu.waitlist.position(vehicleNumber); position > 0 {
+		return position, domain.Reservation{}, nil
+	}
+	for _, r := range pu.pl.Reservations {
+		if r.Waitlisted && r.Status == domain.ReservationHeld && r.VehicleNumber == vehicleNumber {
+			return 0, *r, nil
+		}
+	}
+	return 0, domain.Reservation{}, opError("waitlist entry", request, ErrVehicleNotFound)
+}
+
+// serveWaitlist holds free spots for the vehicles at the front of the waitlist until none
+// of them fits. Callers must hold pl.Mutx.
+func (pu *parkinglotUsecaseImpl) serveWaitlist() error {
+	if pu.waitlist == nil {
+		return nil
+	}
+
+	for served := true; served; {
+		served = false
+		for _, w := range pu.waitlist.heads() {
+			now := pu.now()
+			r := domain.Reservation{
+				VehicleNumber: w.vehicleNumber,
+				VehicleType:   w.vehicleType,
+				Start:         now,
+				End:           now.Add(pu.waitlist.claim),
+				Status:        domain.ReservationBooked,
+				Waitlisted:    true,
+			}
+			if spec, ok := constants.Lookup(w.vehicleType); ok && spec.SpotCount() > 1 {
+				r.SpotCount = spec.SpotCount()
+			}
+			for _, spotType := range pu.compat.SpotTypes(w.vehicleType) {
+				if pu.selectSpots(spotType, max(r.SpotCount, 1)) != nil {
+					r.SpotType = spotType
+					break
+				}
+			}
+			if r.SpotType == "" {
+				continue
+			}
+
+			if err := pu.addReservation(&r); err != nil {
+				return err
+			}
+			pu.waitlist.remove(w.vehicleNumber)
+			if err := pu.holdReservation(&r); err != nil {
+				return err
+			}
+			if notify := w.notify; notify != nil {
+				pu.offers = append(pu.offers, func() { notify(r) })
+			}
+			served = true
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"submit_do_it/constants"
+	"submit_do_it/domain"
+)
+
+// offers collects the reservations a waitlist hands out, one channel per vehicle.
+type offers map[string]chan domain.Reservation
+
+func (o offers) notify(vehicleNumber string) func(domain.Reservation) {
+	ch := make(chan domain.Reservation, 1)
+	o[vehicleNumber] = ch
+	return func(r domain.Reservation) { ch <- r }
+}
+
+// offered returns the reservation offered to the vehicle. Offers are delivered before the
+// operation that made them returns, so there is nothing to wait for.
+func (o offers) offered(t *testing.T, vehicleNumber string) domain.Reservation {
+	t.Helper()
+	select {
+	case r := <-o[vehicleNumber]:
+		return r
+	default:
+		t.Fatalf("no offer for %s", vehicleNumber)
+		return domain.Reservation{}
+	}
+}
+
+func TestParkinglotUsecaseImpl_JoinWaitlist(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1"}}},
+		WithClock(func() time.Time { return clock }),
+		WithWaitlist(WaitlistFIFO, 5*time.Minute))
+	parked, _ := u.Park(constants.Automobile, "CAR1")
+	if _, err := u.Park(constants.Automobile, "CAR2"); !errors.Is(err, ErrLotFull) {
+		t.Fatalf("Park into a full lot: %v", err)
+	}
+
+	o := offers{}
+	for i, number := range []string{"CAR2", "CAR3"} {
+		if position, err := u.JoinWaitlist(constants.Automobile, number, 0, o.notify(number)); err != nil || position != i+1 {
+			t.Fatalf("JoinWaitlist(%s) = %d, %v", number, position, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		vt     constants.VehicleType
+		number string
+		want   error
+	}{
+		{"Parked", constants.Automobile, "CAR1", ErrAlreadyParked},
+		{"Waiting", constants.Automobile, "CAR2", ErrAlreadyWaiting},
+		{"UnknownType", "Z", "CAR4", ErrUnknownVehicleType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := u.JoinWaitlist(tt.vt, tt.number, 0, nil); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := u.UnparkTicket(parked.ID); err != nil {
+		t.Fatal(err)
+	}
+	offer := o.offered(t, "CAR2")
+	if offer.Status != domain.ReservationHeld || offer.SpotID != "0-0-0" || !offer.End.Equal(clock.Add(5*time.Minute)) {
+		t.Errorf("offer to CAR2 = %+v", offer)
+	}
+	if position, r, err := u.WaitlistEntry("CAR2"); err != nil || position != 0 || r.ID != offer.ID {
+		t.Errorf("WaitlistEntry(CAR2) = %d, %+v, %v, want the offer", position, r, err)
+	}
+	if position, _, err := u.WaitlistEntry("CAR3"); err != nil || position != 1 {
+		t.Errorf("WaitlistEntry(CAR3) = %d, %v, want position 1", position, err)
+	}
+	if _, err := u.Park(constants.Automobile, "WALKIN"); !errors.Is(err, ErrLotFull) {
+		t.Errorf("walk-in took the offered spot: %v", err)
+	}
+
+	// CAR2 does not claim its spot in time, so it passes to CAR3.
+	clock = clock.Add(5 * time.Minute)
+	if got := u.AvailableSpot(constants.Automobile); got != 0 {
+		t.Errorf("AvailableSpot(A) after the claim window = %d, want 0", got)
+	}
+	if _, err := u.Park(constants.Automobile, "CAR2"); !errors.Is(err, ErrLotFull) {
+		t.Errorf("CAR2 parked after its claim window: %v", err)
+	}
+	if _, _, err := u.WaitlistEntry("CAR2"); !errors.Is(err, ErrVehicleNotFound) {
+		t.Errorf("WaitlistEntry(CAR2) after the claim window: %v", err)
+	}
+	offer = o.offered(t, "CAR3")
+	if ticket, err := u.Park(constants.Automobile, "CAR3"); err != nil || ticket.ReservationID != offer.ID {
+		t.Errorf("Park(CAR3) = %+v, %v", ticket, err)
+	}
+}
+
+func TestParkinglotUsecaseImpl_JoinWaitlist_Priority(t *testing.T) {
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1"}}}, WithWaitlist(WaitlistPriority, time.Minute))
+	parked, _ := u.Park(constants.Automobile, "CAR1")
+
+	o := offers{}
+	joins := []struct {
+		number   string
+		priority int
+		position int
+	}{
+		{"CAR2", 0, 1},
+		{"CAR3", 5, 1},
+		{"CAR4", 5, 2},
+	}
+	for _, j := range joins {
+		if position, err := u.JoinWaitlist(constants.Automobile, j.number, j.priority, o.notify(j.number)); err != nil || position != j.position {
+			t.Fatalf("JoinWaitlist(%s) = %d, %v, want position %d", j.number, position, err, j.position)
+		}
+	}
+	if err := u.LeaveWaitlist("CAR3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.LeaveWaitlist("CAR3"); !errors.Is(err, ErrVehicleNotFound) {
+		t.Errorf("leave twice: %v", err)
+	}
+
+	if _, err := u.Unpark(parked.SpotID, "CAR1"); err != nil {
+		t.Fatal(err)
+	}
+	if offer := o.offered(t, "CAR4"); offer.SpotID != parked.SpotID {
+		t.Errorf("offer to CAR4 = %+v", offer)
+	}
+	if position, err := u.JoinWaitlist(constants.Automobile, "CAR4", 0, nil); !errors.Is(err, ErrAlreadyReserved) {
+		t.Errorf("JoinWaitlist with a spot held = %d, %v", position, err)
+	}
+}
+
+func TestParkinglotUsecaseImpl_JoinWaitlist_Disabled(t *testing.T) {
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1"}}})
+	if _, err := u.JoinWaitlist(constants.Automobile, "CAR1", 0, nil); !errors.Is(err, ErrWaitlistDisabled) {
+		t.Errorf("JoinWaitlist = %v", err)
+	}
+	if err := u.LeaveWaitlist("CAR1"); !errors.Is(err, ErrWaitlistDisabled) {
+		t.Errorf("LeaveWaitlist = %v", err)
+	}
+	if _, _, err := u.WaitlistEntry("CAR1"); !errors.Is(err, ErrWaitlistDisabled) {
+		t.Errorf("WaitlistEntry = %v", err)
+	}
+}
+
+func TestParkinglotUsecaseImpl_JoinWaitlist_FreeSpot(t *testing.T) {
+	u := NewParkingLotUsecaseFromFloors([][][]string{{{"A-1"}}}, WithWaitlist(WaitlistFIFO, time.Minute))
+	o := offers{}
+	if position, err := u.JoinWaitlist(constants.Automobile, "CAR1", 0, o.notify("CAR1")); err != nil || position != 0 {
+		t.Fatalf("JoinWaitlist with a free spot = %d, %v", position, err)
+	}
+	offer := o.offered(t, "CAR1")
+	if ticket, err := u.Park(constants.Automobile, "CAR1"); err != nil || ticket.ReservationID != offer.ID {
+		t.Errorf("Park(CAR1) = %+v, %v", ticket, err)
+	}
+}
+
+func TestParseWaitlistOrder(t *testing.T) {
+	for in, want := range map[string]WaitlistOrder{"fifo": WaitlistFIFO, "Priority": WaitlistPriority} {
+		if got, err := ParseWaitlistOrder(in); err != nil || got != want {
+			t.Errorf("ParseWaitlistOrder(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseWaitlistOrder("lifo"); err == nil {
+		t.Error("ParseWaitlistOrder(lifo) succeeded")
+	}
+}